@@ -22,41 +22,340 @@ Defaults to creating a base in the platform directory.`,
 }
 
 var imageUri string
-var port int
-var protocol string
 
 func init() {
 	createCmd.AddCommand(baseCmd)
 
 	baseCmd.Flags().StringVarP(&imageUri, "image", "i", "", "Set image e.g nginx:1.7.9")
-	baseCmd.Flags().IntVarP(&port, "port", "p", 8080, "Set container port")
-	baseCmd.Flags().StringVar(&protocol, "protocol", "TCP", "Set protocol")
-	baseCmd.Flags().StringVar(IngressFlag(), "ingress", "", "Enable ingress resource generation with given host")
+	baseCmd.Flags().StringVar(FromDockerfileFlag(), "from-dockerfile", "", "Dockerfile to infer the ports, health check probe and image version from")
+	baseCmd.Flags().StringArrayVarP(PortsFlag(), "port", "p", []string{}, "Set container port as [name=]port[/protocol] e.g http=8080/TCP. Can be repeated, naming each port (default 8080)")
+	baseCmd.Flags().StringVar(ProtocolFlag(), "protocol", "TCP", "Set protocol for ports without one")
+	baseCmd.Flags().StringVar(ServiceTypeFlag(), "service-type", "", "Service type: ClusterIP, NodePort, LoadBalancer or Headless (default ClusterIP, or Headless for a StatefulSet)")
+	addIngressFlags(baseCmd)
+	baseCmd.Flags().StringVar(ServiceAccountFlag(), "service-account", "", "Create a service account with the given name for the application")
+	addProbeFlags(baseCmd)
+	addCommonFlags(baseCmd)
+	baseCmd.Flags().StringVar(SecurityProfileFlag(), "security-profile", "none", "Pod and container security context: restricted, baseline or none. Restricted runs as non root with a read only root filesystem, mounting an emptyDir at /tmp")
+	addVolumeFlags(baseCmd)
+	addContainerFlags(baseCmd)
+	baseCmd.Flags().BoolVar(StatefulFlag(), "stateful", false, "Create a StatefulSet with a persistent volume claim instead of a Deployment")
+	baseCmd.Flags().StringVar(StorageSizeFlag(), "storage", "1Gi", "Storage requested by the StatefulSet volume claim")
+	baseCmd.Flags().StringVar(DataPathFlag(), "dataPath", "/data", "Folder for mounting the StatefulSet volume claim")
+	baseCmd.Flags().StringVar(KindFlag(), "kind", "deployment", "Workload kind: deployment, statefulset, daemonset, job or cronjob")
+	baseCmd.Flags().StringVar(ScheduleFlag(), "schedule", "0 * * * *", "Cron schedule for a cronjob")
+	baseCmd.Flags().StringVar(ConcurrencyPolicyFlag(), "concurrencyPolicy", "Forbid", "Concurrency policy for a cronjob: Allow, Forbid or Replace")
 }
 
 func newBaseCommand(_ *cobra.Command, args []string) {
 	resourceFiles := fs.NewFileMap()
+	kind := workloadKind()
 	app := input.Application{
-		Name:          args[0],
-		Stateful:      false,
-		Image:         useDefault(args[0]+":latest", imageUri),
-		ContainerName: args[0],
-		ContainerPort: port,
-		Protocol:      "TCP",
-		Host:          Ingress(),
-		Replicas:      1,
+		Name:              args[0],
+		Kind:              kind,
+		Stateful:          kind == input.KindStatefulSet,
+		Schedule:          Schedule(),
+		ConcurrencyPolicy: ConcurrencyPolicy(),
+		Image:             useDefault(args[0]+":latest", imageUri),
+		ContainerName:     args[0],
+		Ports:             containerPorts(kind),
+		ServiceType:       baseServiceType(kind),
+		Replicas:          1,
+		ServiceAccount:    ServiceAccount(),
+		DataPath:          DataPath(),
+		StorageSize:       StorageSize(),
+	}
+	dockerfile := readDockerfile()
+	if dockerfile != nil {
+		setFromDockerfile(&app, dockerfile)
+	}
+	if len(Ingress()) > 0 {
+		app.Ingress = ingress(app.Ports)
+	}
+	setProbes(&app, true)
+	if dockerfile != nil && app.LivenessProbe == nil {
+		app.LivenessProbe = dockerfile.HealthCheck
+	}
+	setSecurityProfile(&app)
+	addContainers(&app)
+	for _, v := range volumes(kind, nil) {
+		if _, ok := input.FindVolume(app.Volumes, v.Name); ok {
+			log.Fatalf("Volume %s is already created by the base", v.Name)
+		}
+		app.Volumes = append(app.Volumes, v)
 	}
 
 	log.Infof("Initializing current directory for application %s", app.Name)
 
 	createDirectory()
 
-	createBase(app, resourceFiles, kustomization.BaseGenerators(Ingress() != ""))
-	kustomization.Create(input.NewKustomization(resourceFiles.GetFilenames(), ""), resourceFiles)
+	createBase(app, resourceFiles, kustomization.BaseGenerators(app))
+	k := input.NewKustomization(resourceFiles.GetFilenames(), "")
+	if existing, err := kustomization.Read(fs.Get(), filepath.Join(Directory(), "base")); err == nil {
+		log.Infof("Merging into existing base kustomization")
+		k = existing
+		for _, file := range resourceFiles.GetFilenames() {
+			k.AddResource(file)
+		}
+	}
+	k.AddCommon(Labels(), Annotations())
+	kustomization.Create(k, resourceFiles)
 
 	resourceFiles.WriteAll(Directory(), "base")
 }
 
+func readDockerfile() *input.Dockerfile {
+	if FromDockerfile() == "" {
+		return nil
+	}
+	dockerfile, err := input.ReadDockerfile(fs.Get(), FromDockerfile())
+	if err != nil {
+		log.Fatalf("Could not read Dockerfile: %v", err)
+	}
+	return dockerfile
+}
+
+// setFromDockerfile sets the ports and image version from a Dockerfile unless given by flags
+func setFromDockerfile(app *input.Application, dockerfile *input.Dockerfile) {
+	if len(Ports()) == 0 && len(dockerfile.Ports) > 0 {
+		app.Ports = dockerfile.Ports
+	}
+	if imageUri != "" {
+		return
+	}
+	if dockerfile.Version == "" {
+		log.Warnf("Dockerfile has no %s label, using image %s. Set the image with --image", input.VersionLabel, app.Image)
+		return
+	}
+	app.Image = app.Name + ":" + dockerfile.Version
+}
+
+// setSecurityProfile sets the security profile of the application, adding a writable /tmp for a
+// read only root filesystem
+func setSecurityProfile(app *input.Application) {
+	profile, err := input.ParseSecurityProfile(SecurityProfile())
+	if err != nil {
+		log.Fatalf("Security profile flag is not correctly defined: %v", err)
+	}
+	if profile == input.SecurityNone {
+		return
+	}
+	app.Security = &input.SecurityContext{Profile: profile}
+	if app.Security.Restricted() {
+		app.Volumes = append(app.Volumes, input.Volume{Name: "tmp", MountPath: "/tmp"})
+	}
+}
+
+func workloadKind() string {
+	kind, ok := input.ParseKind(Kind())
+	if !ok {
+		log.Fatalf("Unknown workload kind %s. Expected deployment, statefulset, daemonset, job or cronjob", Kind())
+	}
+	if Stateful() {
+		if kind != input.KindDeployment && kind != input.KindStatefulSet {
+			log.Fatalf("Cannot create a stateful %s", kind)
+		}
+		kind = input.KindStatefulSet
+	}
+	if kind == input.KindCronJob {
+		validateConcurrencyPolicy(ConcurrencyPolicy())
+	}
+	return kind
+}
+
+func baseServiceType(kind string) string {
+	if ServiceType() == "" {
+		return ""
+	}
+	serviceType, err := input.ParseServiceType(ServiceType())
+	if err != nil {
+		log.Fatalf("Service type flag is not correctly defined: %v", err)
+	}
+	if input.IsBatch(kind) {
+		log.Warnf("Ignoring service type as a %s has no service", kind)
+	}
+	if kind == input.KindStatefulSet && serviceType != input.ServiceHeadless {
+		log.Fatalf("Cannot create a %s service for a StatefulSet, which requires a headless service", serviceType)
+	}
+	return serviceType
+}
+
+func containerPorts(kind string) []input.Port {
+	definitions := Ports()
+	if len(definitions) == 0 {
+		if input.IsBatch(kind) {
+			return nil
+		}
+		definitions = []string{"8080"}
+	}
+
+	ports, err := input.ParsePorts(definitions, Protocol())
+	if err != nil {
+		log.Fatalf("Port flag is not correctly defined: %v", err)
+	}
+	return ports
+}
+
+func addProbeFlags(c *cobra.Command) {
+	c.Flags().StringToStringVar(LivenessFlag(), "liveness", map[string]string{}, "Liveness probe. For example, 'http=/healthz,port=http,initialDelaySeconds=10', 'tcp=8080' or 'exec=cat /tmp/healthy' with periodSeconds, timeoutSeconds, failureThreshold and successThreshold")
+	c.Flags().StringToStringVar(ReadinessFlag(), "readiness", map[string]string{}, "Readiness probe, set as for --liveness")
+	c.Flags().StringToStringVar(StartupFlag(), "startup", map[string]string{}, "Startup probe, set as for --liveness")
+}
+
+// setProbes parses the probe flags into the application. Probes require a handler unless they only
+// patch the timings of an existing probe. Probes of a sidecar require a port, as its ports are not known.
+func setProbes(app *input.Application, handlerRequired bool) {
+	ports := app.Ports
+	if app.ContainerName != "" && app.ContainerName != app.Name {
+		ports = nil
+	}
+	probes := []struct {
+		name    string
+		options map[string]string
+		probe   **input.Probe
+	}{
+		{"liveness", Liveness(), &app.LivenessProbe},
+		{"readiness", Readiness(), &app.ReadinessProbe},
+		{"startup", Startup(), &app.StartupProbe},
+	}
+	for _, p := range probes {
+		probe, err := input.ParseProbe(p.options, ports)
+		if err != nil && ports == nil && len(app.Ports) > 0 {
+			log.Fatalf("%s flag is not correctly defined: set the port of container %s e.g http=/healthz,port=8080 or tcp=8080", p.name, app.ContainerName)
+		}
+		if err != nil {
+			log.Fatalf("%s flag is not correctly defined: %v", p.name, err)
+		}
+		if probe != nil && probe.Handler == "" && handlerRequired {
+			log.Fatalf("%s flag is not correctly defined: one of http, tcp or exec must be set", p.name)
+		}
+		*p.probe = probe
+	}
+}
+
+func addCommonFlags(c *cobra.Command) {
+	c.Flags().StringToStringVar(LabelsFlag(), "label", map[string]string{}, "Labels added to every resource and selector through commonLabels. For example, 'team=payments,cost-center=42'")
+	c.Flags().StringToStringVar(AnnotationsFlag(), "annotation", map[string]string{}, "Annotations added to every resource through commonAnnotations. For example, 'owner=payments@example.com'")
+}
+
+func addVolumeFlags(c *cobra.Command) {
+	c.Flags().StringArrayVar(VolumesFlag(), "volume", []string{}, "Persistent volume claim mounted in the container as name=/path with optional size and storage class e.g data=/data,size=10Gi,class=standard. Can be repeated")
+	c.Flags().StringArrayVar(EmptyDirsFlag(), "emptyDir", []string{}, "Scratch volume mounted in the container as name=/path e.g cache=/var/cache. Can be repeated")
+}
+
+// volumes parses the volume flags for a workload of the given kind. Claims without a size request the
+// --storage size, unless the claim exists in the given base volumes, in which case only its size and
+// storage class are set. DaemonSets cannot have claims, as the pod on every node would mount the same
+// ReadWriteOnce claim.
+func volumes(kind string, base []input.Volume) []input.Volume {
+	var parsed []input.Volume
+	names := map[string]bool{}
+	for _, definitions := range []struct {
+		values []string
+		claim  bool
+	}{{Volumes(), true}, {EmptyDirs(), false}} {
+		for _, definition := range definitions.values {
+			v, err := input.ParseVolume(definition, definitions.claim)
+			if err != nil {
+				log.Fatalf("Volume flag is not correctly defined: %v", err)
+			}
+			if names[v.Name] {
+				log.Fatalf("Volume %s is set more than once", v.Name)
+			}
+			names[v.Name] = true
+			if v.Claim && kind == input.KindDaemonSet {
+				log.Fatalf("Cannot add volume claim %s to a DaemonSet as every pod would mount the same claim. Use --emptyDir instead", v.Name)
+			}
+
+			if existing, ok := input.FindVolume(base, v.Name); ok {
+				if !existing.Claim || !v.Claim {
+					log.Fatalf("Cannot override volume %s of the base, only the size and storage class of a claim can be set", v.Name)
+				}
+				if v.MountPath != "" && v.MountPath != existing.MountPath {
+					log.Fatalf("Cannot mount volume %s at %s as the base mounts it at %s", v.Name, v.MountPath, existing.MountPath)
+				}
+				if v.Size == "" && v.StorageClass == "" {
+					log.Fatalf("Volume %s is already mounted by the base. Set its size or class to override them", v.Name)
+				}
+				v.MountPath = existing.MountPath
+			} else {
+				if v.MountPath == "" {
+					log.Fatalf("Volume flag is not correctly defined: volume %s has no mount path e.g %s=/data", v.Name, v.Name)
+				}
+				if v.Claim && v.Size == "" {
+					v.Size = StorageSize()
+				}
+			}
+			parsed = append(parsed, v)
+		}
+	}
+	return parsed
+}
+
+func addContainerFlags(c *cobra.Command) {
+	c.Flags().StringArrayVar(InitContainersFlag(), "init-container", []string{}, "Init container run before the application as name=image[,command=<command>] e.g migrate=app:latest,command=./migrate up. Can be repeated")
+	c.Flags().StringArrayVar(SidecarsFlag(), "sidecar", []string{}, "Sidecar container run alongside the application as name=image[,command=<command>] e.g proxy=envoyproxy/envoy:v1.30.1. Can be repeated")
+}
+
+// addContainers parses the init and sidecar container flags into the application, checking the
+// container names are unique in the pod
+func addContainers(app *input.Application) {
+	names := map[string]bool{app.ContainerName: true}
+	for _, c := range append(app.InitContainers, app.Sidecars...) {
+		names[c.Name] = true
+	}
+	for _, definitions := range []struct {
+		values     []string
+		containers *[]input.Container
+	}{{InitContainers(), &app.InitContainers}, {Sidecars(), &app.Sidecars}} {
+		for _, definition := range definitions.values {
+			c, err := input.ParseContainer(definition)
+			if err != nil {
+				log.Fatalf("Container flag is not correctly defined: %v", err)
+			}
+			if names[c.Name] {
+				log.Fatalf("Container %s is already defined", c.Name)
+			}
+			names[c.Name] = true
+			*definitions.containers = append(*definitions.containers, c)
+		}
+	}
+	if len(app.Sidecars) > 0 && app.Batch() {
+		log.Warnf("Sidecars of a %s must exit for the %s to complete", app.Kind, app.Kind)
+	}
+}
+
+func addIngressFlags(c *cobra.Command) {
+	c.Flags().StringArrayVar(IngressFlag(), "ingress", []string{}, "Enable ingress resource generation with given host and optional path e.g example.com/api. Can be repeated")
+	c.Flags().StringVar(IngressPortFlag(), "ingressPort", "", "Name or number of the port for the ingress backend. Defaults to the first port")
+	c.Flags().StringVar(IngressClassFlag(), "ingressClass", "", "Ingress class name e.g nginx")
+	c.Flags().StringVar(IngressTLSFlag(), "ingressTls", "", "Secret containing the TLS certificate for the ingress hosts")
+	c.Flags().StringVar(IngressPathTypeFlag(), "ingressPathType", "Prefix", "Path type for ingress paths: Prefix, Exact or ImplementationSpecific")
+	c.Flags().StringToStringVar(IngressAnnotationsFlag(), "ingressAnnotation", map[string]string{}, "Ingress annotations. For example, 'nginx.ingress.kubernetes.io/proxy-body-size=8m'")
+}
+
+func ingress(ports []input.Port) *input.Ingress {
+	i, err := input.NewIngress(Ingress(), IngressPathType())
+	if err != nil {
+		log.Fatalf("Ingress flag is not correctly defined: %v", err)
+	}
+	i.Port, err = input.FindPort(ports, IngressPort())
+	if err != nil {
+		log.Fatalf("Cannot create ingress: %v", err)
+	}
+	i.ClassName = IngressClass()
+	i.TLSSecret = IngressTLS()
+	i.Annotations = IngressAnnotations()
+	return i
+}
+
+func validateConcurrencyPolicy(policy string) {
+	switch policy {
+	case "Allow", "Forbid", "Replace":
+	default:
+		log.Fatalf("Unknown concurrency policy %s. Expected Allow, Forbid or Replace", policy)
+	}
+}
+
 func useDefault(def string, flag string) string {
 	if len(flag) == 0 {
 		return def
@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"github.com/azunymous/easymodo/fs"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -158,3 +159,433 @@ func TestCreatesFlagPlatformDir(t *testing.T) {
 	*DirectoryFlag() = "platform"
 	cleanup()
 }
+
+func TestCreatesStatefulSetFile(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"--stateful",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, baseDirDefault, "statefulset.yaml")
+	stat, fErr := fs.Get().Stat(p)
+	assert.Nil(t, fErr)
+	assert.False(t, stat.IsDir())
+
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-stateful", "statefulset.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+
+	exists, _ := afero.Exists(fs.Get(), path.Join(platformDirDefault, baseDirDefault, "deployment.yaml"))
+	assert.False(t, exists)
+	cleanup()
+}
+
+func TestCreatesHeadlessServiceFileForStatefulSet(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"--stateful",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"service.yaml", "kustomization.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-stateful", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, baseDirDefault, file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesCronJobFileWithoutService(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"--kind", "cronjob",
+		"--schedule", "*/5 * * * *",
+		"--concurrencyPolicy", "Replace",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"cronjob.yaml", "kustomization.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-cronjob", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, baseDirDefault, file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+
+	exists, _ := afero.Exists(fs.Get(), path.Join(platformDirDefault, baseDirDefault, "service.yaml"))
+	assert.False(t, exists)
+	cleanup()
+}
+
+func TestCreatesDaemonSetFile(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"--kind", "DaemonSet",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, baseDirDefault, "daemonset.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-daemonset", "daemonset.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+
+	exists, _ := afero.Exists(fs.Get(), path.Join(platformDirDefault, baseDirDefault, "service.yaml"))
+	assert.True(t, exists)
+	cleanup()
+}
+
+func TestCreatesDeploymentAndServiceFilesWithNamedPorts(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"-p", "http=8080",
+		"-p", "metrics=9090",
+		"-p", "dns=53/udp",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"deployment.yaml", "service.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-with-ports", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, baseDirDefault, file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesDeploymentFileWithProbes(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"-p", "http=8080",
+		"--liveness", "http=/healthz,initialDelaySeconds=10",
+		"--readiness", "tcp=",
+		"--startup", "exec=cat /tmp/ready,failureThreshold=30",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, baseDirDefault, "deployment.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-with-probes", "deployment.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesServiceAccountFile(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"--service-account", "app-sa",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"deployment.yaml", "serviceaccount.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-with-service-account", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, baseDirDefault, file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesServiceFileWithServiceType(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"--service-type", "nodeport",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, baseDirDefault, "service.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-with-service-type", "service.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesDeploymentFileFromDockerfile(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	logs := new(bytes.Buffer)
+	log.SetOutput(logs)
+	defer log.SetOutput(w)
+	dockerfile, _ := ioutil.ReadFile(filepath.Join("testdata", "base-from-dockerfile", "Dockerfile"))
+	_ = afero.WriteFile(fs.Get(), "Dockerfile", dockerfile, 0644)
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"api",
+		"--from-dockerfile", "Dockerfile",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, baseDirDefault, "deployment.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-from-dockerfile", "deployment.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	assert.NotContains(t, logs.String(), "label")
+	cleanup()
+}
+
+func TestCreatesDeploymentFileFromDockerfileWithoutVersionLabel(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	logs := new(bytes.Buffer)
+	log.SetOutput(logs)
+	defer log.SetOutput(w)
+	_ = afero.WriteFile(fs.Get(), "Dockerfile", []byte("FROM alpine:3.18\nEXPOSE 80\n"), 0644)
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"api",
+		"--from-dockerfile", "Dockerfile",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, baseDirDefault, "deployment.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Contains(t, string(actual), "image: api:latest\n")
+	assert.Contains(t, logs.String(), "Dockerfile has no org.opencontainers.image.version label, using image api:latest")
+	cleanup()
+}
+
+func TestCreatesDeploymentFileFromDockerfileWithContinuations(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	dockerfile, _ := ioutil.ReadFile(filepath.Join("testdata", "base-from-dockerfile-with-continuations", "Dockerfile"))
+	_ = afero.WriteFile(fs.Get(), "Dockerfile", dockerfile, 0644)
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"api",
+		"--from-dockerfile", "Dockerfile",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, baseDirDefault, "deployment.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-from-dockerfile", "deployment.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesDeploymentFileFromDockerfileWithEmptyContinuation(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	_ = afero.WriteFile(fs.Get(), "Dockerfile", []byte("FROM a\n\\\n\nEXPOSE 80"), 0644)
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"api",
+		"--from-dockerfile", "Dockerfile",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, baseDirDefault, "deployment.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Contains(t, string(actual), "containerPort: 80\n")
+	cleanup()
+}
+
+func TestCreatesDeploymentFileWithRestrictedSecurityProfile(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"--security-profile", "restricted",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, baseDirDefault, "deployment.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-with-security-profile", "deployment.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesBaseWithVolumes(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"--volume", "data=/data,size=5Gi,class=standard",
+		"--emptyDir", "cache=/var/cache",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"deployment.yaml", "pvc.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-with-volumes", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, baseDirDefault, file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesStatefulSetWithVolumeClaimTemplates(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"db",
+		"--stateful",
+		"--volume", "logs=/logs,size=2Gi,class=fast",
+		"--emptyDir", "cache=/cache",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-stateful-with-volumes", "statefulset.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, baseDirDefault, "statefulset.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	exists, _ := afero.Exists(fs.Get(), path.Join(platformDirDefault, baseDirDefault, "pvc.yaml"))
+	assert.False(t, exists)
+	cleanup()
+}
+
+func TestCreatesDeploymentFileWithInitContainersAndSidecars(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+		"--init-container", "migrate=app:latest,command=./migrate up",
+		"--sidecar", "proxy=envoyproxy/envoy:v1.30.1",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, baseDirDefault, "deployment.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", "base-with-containers", "deployment.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestMergesBaseIntoExistingBaseKustomization(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	existing := "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n- configmap.yaml\n- deployment.yaml\nbuildMetadata:\n- originAnnotations\n"
+	_ = afero.WriteFile(fs.Get(), path.Join(platformDirDefault, baseDirDefault, "kustomization.yaml"), []byte(existing), 0644)
+	cmd.SetArgs([]string{
+		"create",
+		"base",
+		"app",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, baseDirDefault, "kustomization.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Equal(t, "apiVersion: kustomize.config.k8s.io/v1beta1\nkind: Kustomization\nresources:\n- configmap.yaml\n- deployment.yaml\n- service.yaml\nbuildMetadata:\n- originAnnotations\n", string(actual))
+	cleanup()
+}
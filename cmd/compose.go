@@ -0,0 +1,144 @@
+package cmd
+
+import (
+	"github.com/azunymous/easymodo/fs"
+	"github.com/azunymous/easymodo/input"
+	"github.com/azunymous/easymodo/kustomization"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"path"
+)
+
+// composeCmd represents the compose command for importing a docker-compose file
+var composeCmd = &cobra.Command{
+	Use:   "compose [docker-compose file]",
+	Short: "Create bases and overlays from a docker-compose file",
+	Long: `Creates a base and an overlay for each service in a docker-compose file, in a folder named
+after the service, and a kustomization grouping the overlays together.
+
+Images, ports, environment variables, env files and volumes are imported. Env files are added to
+the overlay as secrets and named volumes are backed by persistent volume claims.
+
+e.g easymodo import compose docker-compose.yml -s dev`,
+	Run:  newComposeCommand,
+	Args: cobra.MaximumNArgs(1),
+}
+
+func init() {
+	importCmd.AddCommand(composeCmd)
+
+	composeCmd.Flags().StringVarP(SuffixFlag(), "suffix", "s", "dev", "Suffix to use for the namespace of each overlay")
+	composeCmd.Flags().StringVar(StorageSizeFlag(), "storage", "1Gi", "Storage requested by the volume claims for named volumes")
+	composeCmd.Flags().StringVarP(OutputFlag(), "output", "o", ".", "Output folder for the service folders and group kustomization file")
+}
+
+func newComposeCommand(_ *cobra.Command, args []string) {
+	composeFile := "docker-compose.yml"
+	if len(args) > 0 {
+		composeFile = args[0]
+	}
+	compose, err := input.ReadCompose(fs.Get(), composeFile)
+	if err != nil {
+		log.Fatalf("Could not import compose file: %v", err)
+	}
+
+	outputDir := path.Clean(Output())
+	suffix := useDefault("dev", Suffix())
+	group := input.Kustomization{
+		Res:     []string{},
+		Patches: []string{},
+		Config:  map[string][]string{},
+		Secrets: map[string][]string{},
+	}
+
+	for _, serviceName := range compose.ServiceNames() {
+		app := composeApp(serviceName, compose.Services[serviceName])
+		platformDir := path.Join(outputDir, app.Name, Directory())
+
+		log.Infof("Importing compose service %s as application %s", serviceName, app.Name)
+		baseFiles := fs.NewFileMap()
+		createBase(app, baseFiles, kustomization.BaseGenerators(app))
+		kustomization.Create(input.NewKustomization(baseFiles.GetFilenames(), ""), baseFiles)
+		baseFiles.WriteAll(platformDir, "base")
+
+		createComposeOverlay(app, compose.Services[serviceName], path.Dir(composeFile), suffix, platformDir)
+		group.AddResource(path.Join(app.Name, Directory(), suffix))
+	}
+
+	groupFiles := fs.NewFileMap()
+	kustomization.Create(&group, groupFiles)
+	groupFiles.WriteAll("", outputDir)
+	log.Info("Created kustomization yaml in ", outputDir)
+}
+
+// composeApp creates an application from a compose service
+func composeApp(serviceName string, service input.ComposeService) input.Application {
+	name := input.KubernetesName(serviceName)
+	if service.Image == "" {
+		log.Warnf("Service %s has no image, using %s:latest. Images built by compose need pushing to a registry", serviceName, name)
+	}
+
+	ports, err := service.ContainerPorts()
+	if err != nil {
+		log.Fatalf("Cannot import ports of service %s: %v", serviceName, err)
+	}
+	if len(ports) == 0 {
+		log.Warnf("Service %s has no ports, skipping service generation", serviceName)
+	}
+	volumes, err := service.ContainerVolumes(StorageSize())
+	if err != nil {
+		log.Fatalf("Cannot import volumes of service %s: %v", serviceName, err)
+	}
+
+	return input.Application{
+		Name:          name,
+		Kind:          input.KindDeployment,
+		Image:         useDefault(name+":latest", service.Image),
+		ContainerName: name,
+		Ports:         ports,
+		Env:           service.Env(),
+		Volumes:       volumes,
+		Replicas:      1,
+	}
+}
+
+// createComposeOverlay creates an overlay for the application with the service's env files as a secret
+func createComposeOverlay(app input.Application, service input.ComposeService, composeDir, suffix, platformDir string) {
+	overlayFiles := fs.NewFileMap()
+	k := input.Kustomization{
+		Res:       []string{"../base"},
+		Patches:   []string{},
+		Config:    map[string][]string{},
+		Secrets:   map[string][]string{},
+		Namespace: app.Name + "-" + suffix,
+	}
+	app.Namespace = k.Namespace
+
+	envFiles, err := service.EnvFiles()
+	if err != nil {
+		log.Fatalf("Cannot import env files of service %s: %v", app.Name, err)
+	}
+	for _, envFile := range envFiles {
+		content, err := afero.ReadFile(fs.Get(), path.Join(composeDir, envFile.Path))
+		if err != nil && !envFile.Required {
+			log.Warnf("Skipping env file %s that is not required: %v", envFile.Path, err)
+			continue
+		}
+		if err != nil {
+			log.Fatalf("Could not read env file %s: %v", envFile.Path, err)
+		}
+		overlayFiles.Add(path.Base(envFile.Path), string(content))
+		k.AddSecret(app.Name+"-secret", path.Base(envFile.Path))
+	}
+	if len(k.Secrets) > 0 {
+		err := kustomization.Generate(patchName(app, "secret"), kustomization.DeploymentSecretPatch())(app, overlayFiles)
+		if err != nil {
+			log.Fatalf("could not create deployment patch with given secret file: %v", err)
+		}
+		k.AddPatch(patchName(app, "secret") + ".yaml")
+	}
+
+	kustomization.Create(&k, overlayFiles)
+	overlayFiles.WriteAll(platformDir, suffix)
+}
@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"github.com/azunymous/easymodo/fs"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func setUpComposeFiles(t *testing.T) {
+	for _, file := range []string{"docker-compose.yml", ".env", "worker.env"} {
+		content, err := ioutil.ReadFile(filepath.Join("testdata", "compose", file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		_ = afero.WriteFile(fs.Get(), file, content, 0644)
+	}
+}
+
+func TestImportsComposeServicesAsBasesOverlaysAndGroup(t *testing.T) {
+	cmd, buf, err := setUpCommand()
+	setUpComposeFiles(t)
+	cmd.SetArgs([]string{
+		"import",
+		"compose",
+		"docker-compose.yml",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	files := []string{
+		"kustomization.yaml",
+		filepath.Join("web-app", "platform", "base", "deployment.yaml"),
+		filepath.Join("web-app", "platform", "base", "pvc.yaml"),
+		filepath.Join("web-app", "platform", "dev", "kustomization.yaml"),
+		filepath.Join("db", "platform", "base", "service.yaml"),
+		filepath.Join("db", "platform", "base", "deployment.yaml"),
+	}
+	for _, file := range files {
+		expect, _ := ioutil.ReadFile(filepath.Join("testdata", "compose", file))
+		actual, fErr := afero.ReadFile(fs.Get(), file)
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+
+	exists, _ := afero.Exists(fs.Get(), filepath.Join("worker", "platform", "base", "service.yaml"))
+	assert.False(t, exists)
+	secret, _ := afero.ReadFile(fs.Get(), filepath.Join("web-app", "platform", "dev", ".env"))
+	assert.Equal(t, "SECRET=abc\n", string(secret))
+	secret, _ = afero.ReadFile(fs.Get(), filepath.Join("worker", "platform", "dev", "worker.env"))
+	assert.Equal(t, "QUEUE=jobs\n", string(secret))
+	exists, _ = afero.Exists(fs.Get(), filepath.Join("worker", "platform", "dev", "local.env"))
+	assert.False(t, exists)
+	cleanup()
+}
@@ -16,35 +16,147 @@ func ResetOptionalFlags() {
 	global.context = ""
 
 	global.configFiles = map[string]string{}
+	global.configPath = ""
 	global.secretEnvs = map[string]string{}
 	global.namespaceResource = false
 	global.suffix = ""
 	global.verify = false
-	global.ingress = ""
+	global.ingress = []string{}
 	global.replicas = 1
 	global.image = ""
 	global.kustomizations = []string{}
 	global.limits = map[string]string{}
 	global.requests = map[string]string{}
 	global.output = ""
+	global.stateful = false
+	global.storageSize = "1Gi"
+	global.dataPath = "/data"
+	global.kind = "deployment"
+	global.schedule = "0 * * * *"
+	global.concurrencyPolicy = "Forbid"
+	global.ports = []string{}
+	global.protocol = "TCP"
+	global.ingressPort = ""
+	global.liveness = map[string]string{}
+	global.readiness = map[string]string{}
+	global.startup = map[string]string{}
+	global.autoscale = map[string]string{}
+	global.pdb = map[string]string{}
+	global.networkPolicy = false
+	global.allowFromNamespaces = []string{}
+	global.allowFromApps = []string{}
+	global.restrictEgress = false
+	global.allowToNamespaces = []string{}
+	global.allowToApps = []string{}
+	global.serviceAccount = ""
+	global.grants = []string{}
+	global.ingressClass = ""
+	global.ingressTLS = ""
+	global.ingressPathType = "Prefix"
+	global.ingressAnnotations = map[string]string{}
+	global.serviceType = ""
+	global.serviceAnnotations = map[string]string{}
+	global.fromDockerfile = ""
+	global.securityProfile = "none"
+	global.securityContext = map[string]string{}
+	global.labels = map[string]string{}
+	global.annotations = map[string]string{}
+	global.env = []string{}
+	global.envFiles = []string{}
+	global.secretFiles = map[string]string{}
+	global.secretPath = ""
+	global.ageRecipients = []string{}
+	global.ageKeyFile = ""
+	global.externalSecrets = []string{}
+	global.secretStore = ""
+	global.secretStoreKind = "SecretStore"
+	global.refreshInterval = "1h"
+	global.sealedSecretCert = ""
+	global.volumes = []string{}
+	global.emptyDirs = []string{}
+	global.initContainers = []string{}
+	global.sidecars = []string{}
+	global.container = ""
+	global.nodeSelector = map[string]string{}
+	global.tolerations = []string{}
+	global.antiAffinity = ""
+	global.topologySpread = []string{}
+	global.priorityClass = ""
+	global.imagePatch = false
 }
 
 type Flags struct {
-	configFiles       map[string]string
-	secretEnvs        map[string]string
-	directory         string
-	context           string
-	suffix            string
-	namespace         string
-	namespaceResource bool
-	verify            bool
-	ingress           string
-	replicas          int
-	image             string
-	kustomizations    []string
-	limits            map[string]string
-	requests          map[string]string
-	output            string
+	configFiles         map[string]string
+	configPath          string
+	secretEnvs          map[string]string
+	directory           string
+	context             string
+	suffix              string
+	namespace           string
+	namespaceResource   bool
+	verify              bool
+	ingress             []string
+	replicas            int
+	image               string
+	kustomizations      []string
+	limits              map[string]string
+	requests            map[string]string
+	output              string
+	stateful            bool
+	storageSize         string
+	dataPath            string
+	kind                string
+	schedule            string
+	concurrencyPolicy   string
+	ports               []string
+	protocol            string
+	ingressPort         string
+	liveness            map[string]string
+	readiness           map[string]string
+	startup             map[string]string
+	autoscale           map[string]string
+	pdb                 map[string]string
+	networkPolicy       bool
+	allowFromNamespaces []string
+	allowFromApps       []string
+	restrictEgress      bool
+	allowToNamespaces   []string
+	allowToApps         []string
+	serviceAccount      string
+	grants              []string
+	ingressClass        string
+	ingressTLS          string
+	ingressPathType     string
+	ingressAnnotations  map[string]string
+	serviceType         string
+	serviceAnnotations  map[string]string
+	fromDockerfile      string
+	securityProfile     string
+	securityContext     map[string]string
+	labels              map[string]string
+	annotations         map[string]string
+	env                 []string
+	envFiles            []string
+	secretFiles         map[string]string
+	secretPath          string
+	ageRecipients       []string
+	ageKeyFile          string
+	externalSecrets     []string
+	secretStore         string
+	secretStoreKind     string
+	refreshInterval     string
+	sealedSecretCert    string
+	volumes             []string
+	emptyDirs           []string
+	initContainers      []string
+	sidecars            []string
+	container           string
+	nodeSelector        map[string]string
+	tolerations         []string
+	antiAffinity        string
+	topologySpread      []string
+	priorityClass       string
+	imagePatch          bool
 }
 
 func ConfigFiles() map[string]string {
@@ -55,6 +167,14 @@ func ConfigFilesFlag() *map[string]string {
 	return &global.configFiles
 }
 
+func ConfigPath() string {
+	return global.configPath
+}
+
+func ConfigPathFlag() *string {
+	return &global.configPath
+}
+
 func SecretEnvs() map[string]string {
 	return global.secretEnvs
 }
@@ -103,11 +223,11 @@ func NamespaceResourceFlag() *bool {
 	return &global.namespaceResource
 }
 
-func Ingress() string {
+func Ingress() []string {
 	return global.ingress
 }
 
-func IngressFlag() *string {
+func IngressFlag() *[]string {
 	return &global.ingress
 }
 func Replicas() int {
@@ -164,3 +284,443 @@ func Requests() map[string]string {
 func RequestsFlag() *map[string]string {
 	return &global.requests
 }
+
+func Stateful() bool {
+	return global.stateful
+}
+
+func StatefulFlag() *bool {
+	return &global.stateful
+}
+
+func StorageSize() string {
+	return global.storageSize
+}
+
+func StorageSizeFlag() *string {
+	return &global.storageSize
+}
+
+func DataPath() string {
+	return global.dataPath
+}
+
+func DataPathFlag() *string {
+	return &global.dataPath
+}
+
+func Kind() string {
+	return global.kind
+}
+
+func KindFlag() *string {
+	return &global.kind
+}
+
+func Schedule() string {
+	return global.schedule
+}
+
+func ScheduleFlag() *string {
+	return &global.schedule
+}
+
+func ConcurrencyPolicy() string {
+	return global.concurrencyPolicy
+}
+
+func ConcurrencyPolicyFlag() *string {
+	return &global.concurrencyPolicy
+}
+
+func Ports() []string {
+	return global.ports
+}
+
+func PortsFlag() *[]string {
+	return &global.ports
+}
+
+func Protocol() string {
+	return global.protocol
+}
+
+func ProtocolFlag() *string {
+	return &global.protocol
+}
+
+func IngressPort() string {
+	return global.ingressPort
+}
+
+func IngressPortFlag() *string {
+	return &global.ingressPort
+}
+
+func Liveness() map[string]string {
+	return global.liveness
+}
+
+func LivenessFlag() *map[string]string {
+	return &global.liveness
+}
+
+func Readiness() map[string]string {
+	return global.readiness
+}
+
+func ReadinessFlag() *map[string]string {
+	return &global.readiness
+}
+
+func Startup() map[string]string {
+	return global.startup
+}
+
+func StartupFlag() *map[string]string {
+	return &global.startup
+}
+
+func Autoscale() map[string]string {
+	return global.autoscale
+}
+
+func AutoscaleFlag() *map[string]string {
+	return &global.autoscale
+}
+
+func Pdb() map[string]string {
+	return global.pdb
+}
+
+func PdbFlag() *map[string]string {
+	return &global.pdb
+}
+
+func NetworkPolicy() bool {
+	return global.networkPolicy
+}
+
+func NetworkPolicyFlag() *bool {
+	return &global.networkPolicy
+}
+
+func AllowFromNamespaces() []string {
+	return global.allowFromNamespaces
+}
+
+func AllowFromNamespacesFlag() *[]string {
+	return &global.allowFromNamespaces
+}
+
+func AllowFromApps() []string {
+	return global.allowFromApps
+}
+
+func AllowFromAppsFlag() *[]string {
+	return &global.allowFromApps
+}
+
+func RestrictEgress() bool {
+	return global.restrictEgress
+}
+
+func RestrictEgressFlag() *bool {
+	return &global.restrictEgress
+}
+
+func AllowToNamespaces() []string {
+	return global.allowToNamespaces
+}
+
+func AllowToNamespacesFlag() *[]string {
+	return &global.allowToNamespaces
+}
+
+func AllowToApps() []string {
+	return global.allowToApps
+}
+
+func AllowToAppsFlag() *[]string {
+	return &global.allowToApps
+}
+
+func ServiceAccount() string {
+	return global.serviceAccount
+}
+
+func ServiceAccountFlag() *string {
+	return &global.serviceAccount
+}
+
+func Grants() []string {
+	return global.grants
+}
+
+func GrantsFlag() *[]string {
+	return &global.grants
+}
+
+func IngressClass() string {
+	return global.ingressClass
+}
+
+func IngressClassFlag() *string {
+	return &global.ingressClass
+}
+
+func IngressTLS() string {
+	return global.ingressTLS
+}
+
+func IngressTLSFlag() *string {
+	return &global.ingressTLS
+}
+
+func IngressPathType() string {
+	return global.ingressPathType
+}
+
+func IngressPathTypeFlag() *string {
+	return &global.ingressPathType
+}
+
+func IngressAnnotations() map[string]string {
+	return global.ingressAnnotations
+}
+
+func IngressAnnotationsFlag() *map[string]string {
+	return &global.ingressAnnotations
+}
+
+func ServiceType() string {
+	return global.serviceType
+}
+
+func ServiceTypeFlag() *string {
+	return &global.serviceType
+}
+
+func ServiceAnnotations() map[string]string {
+	return global.serviceAnnotations
+}
+
+func ServiceAnnotationsFlag() *map[string]string {
+	return &global.serviceAnnotations
+}
+
+func FromDockerfile() string {
+	return global.fromDockerfile
+}
+
+func FromDockerfileFlag() *string {
+	return &global.fromDockerfile
+}
+
+func SecurityProfile() string {
+	return global.securityProfile
+}
+
+func SecurityProfileFlag() *string {
+	return &global.securityProfile
+}
+
+func SecurityContext() map[string]string {
+	return global.securityContext
+}
+
+func SecurityContextFlag() *map[string]string {
+	return &global.securityContext
+}
+
+func Labels() map[string]string {
+	return global.labels
+}
+
+func LabelsFlag() *map[string]string {
+	return &global.labels
+}
+
+func Annotations() map[string]string {
+	return global.annotations
+}
+
+func AnnotationsFlag() *map[string]string {
+	return &global.annotations
+}
+
+func Env() []string {
+	return global.env
+}
+
+func EnvFlag() *[]string {
+	return &global.env
+}
+
+func EnvFiles() []string {
+	return global.envFiles
+}
+
+func EnvFilesFlag() *[]string {
+	return &global.envFiles
+}
+
+func SecretFiles() map[string]string {
+	return global.secretFiles
+}
+
+func SecretFilesFlag() *map[string]string {
+	return &global.secretFiles
+}
+
+func SecretPath() string {
+	return global.secretPath
+}
+
+func SecretPathFlag() *string {
+	return &global.secretPath
+}
+
+func AgeRecipients() []string {
+	return global.ageRecipients
+}
+
+func AgeRecipientsFlag() *[]string {
+	return &global.ageRecipients
+}
+
+func AgeKeyFile() string {
+	return global.ageKeyFile
+}
+
+func AgeKeyFileFlag() *string {
+	return &global.ageKeyFile
+}
+
+func ExternalSecrets() []string {
+	return global.externalSecrets
+}
+
+func ExternalSecretsFlag() *[]string {
+	return &global.externalSecrets
+}
+
+func SecretStore() string {
+	return global.secretStore
+}
+
+func SecretStoreFlag() *string {
+	return &global.secretStore
+}
+
+func SecretStoreKind() string {
+	return global.secretStoreKind
+}
+
+func SecretStoreKindFlag() *string {
+	return &global.secretStoreKind
+}
+
+func RefreshInterval() string {
+	return global.refreshInterval
+}
+
+func RefreshIntervalFlag() *string {
+	return &global.refreshInterval
+}
+
+func SealedSecretCert() string {
+	return global.sealedSecretCert
+}
+
+func SealedSecretCertFlag() *string {
+	return &global.sealedSecretCert
+}
+
+func Volumes() []string {
+	return global.volumes
+}
+
+func VolumesFlag() *[]string {
+	return &global.volumes
+}
+
+func EmptyDirs() []string {
+	return global.emptyDirs
+}
+
+func EmptyDirsFlag() *[]string {
+	return &global.emptyDirs
+}
+
+func InitContainers() []string {
+	return global.initContainers
+}
+
+func InitContainersFlag() *[]string {
+	return &global.initContainers
+}
+
+func Sidecars() []string {
+	return global.sidecars
+}
+
+func SidecarsFlag() *[]string {
+	return &global.sidecars
+}
+
+func Container() string {
+	return global.container
+}
+
+func ContainerFlag() *string {
+	return &global.container
+}
+
+func NodeSelector() map[string]string {
+	return global.nodeSelector
+}
+
+func NodeSelectorFlag() *map[string]string {
+	return &global.nodeSelector
+}
+
+func Tolerations() []string {
+	return global.tolerations
+}
+
+func TolerationsFlag() *[]string {
+	return &global.tolerations
+}
+
+func AntiAffinity() string {
+	return global.antiAffinity
+}
+
+func AntiAffinityFlag() *string {
+	return &global.antiAffinity
+}
+
+func TopologySpread() []string {
+	return global.topologySpread
+}
+
+func TopologySpreadFlag() *[]string {
+	return &global.topologySpread
+}
+
+func PriorityClass() string {
+	return global.priorityClass
+}
+
+func PriorityClassFlag() *string {
+	return &global.priorityClass
+}
+
+func ImagePatch() bool {
+	return global.imagePatch
+}
+
+func ImagePatchFlag() *bool {
+	return &global.imagePatch
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/spf13/cobra"
 	"path"
 	"path/filepath"
+	"strings"
 )
 
 // group represents the group command
@@ -35,21 +36,27 @@ func init() {
 
 	groupCmd.Flags().BoolVarP(VerifyFlag(), "verify", "v", false, "Verify kustomizations exist")
 	groupCmd.Flags().StringVarP(OutputFlag(), "output", "o", ".", "Output folder for kustomization file")
+	addCommonFlags(groupCmd)
 }
 
 func newGroupCommand(_ *cobra.Command, _ []string) {
 	resourceFiles := fs.NewFileMap()
 	outputDir := path.Clean(Output())
 
-	k := input.Kustomization{
+	k := &input.Kustomization{
 		Res:     []string{},
 		Patches: []string{},
 		Config:  map[string][]string{},
 		Secrets: map[string][]string{},
 	}
+	if existing, err := kustomization.Read(fs.Get(), outputDir); err == nil {
+		log.Infof("Merging into existing kustomization in %s", outputDir)
+		k = existing
+	}
+	k.AddCommon(Labels(), Annotations())
 
 	for _, kFolder := range Kustomizations() {
-		kFolder = path.Clean(kFolder)
+		kFolder = path.Clean(strings.TrimSpace(kFolder))
 		if Verify() {
 			exists, err := afero.DirExists(fs.Get(), kFolder)
 			if err != nil {
@@ -70,7 +77,7 @@ func newGroupCommand(_ *cobra.Command, _ []string) {
 		k.AddResource(kFolder)
 	}
 
-	kustomization.Create(&k, resourceFiles)
+	kustomization.Create(k, resourceFiles)
 	resourceFiles.WriteAll("", outputDir)
 	log.Info("Created kustomization yaml in ", outputDir)
 }
@@ -234,3 +234,45 @@ func TestCreatesGroupKustomizationFailsWithFile(t *testing.T) {
 	assert.NotNil(t, fErr)
 	cleanup()
 }
+
+func TestCreatesGroupKustomizationWithCommonLabelsAndAnnotations(t *testing.T) {
+	cmd, buf, err := setUpGroupCommand()
+	cmd.SetArgs([]string{
+		"group",
+		"-k", "platform/dev",
+		"--label", "environment=dev",
+		"--annotation", "owner=payments@example.com",
+	})
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	expect, _ := ioutil.ReadFile(filepath.Join("group-dev-with-labels", "kustomization.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), "kustomization.yaml")
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestMergesGroupKustomizationIntoExistingKustomization(t *testing.T) {
+	cmd, buf, err := setUpGroupCommand()
+	cmd.SetArgs([]string{
+		"group",
+		"-k", "platform/dev",
+		"-o", "release",
+		"--label", "environment=dev",
+	})
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	expect, _ := ioutil.ReadFile(filepath.Join("group-dev-existing-output", "kustomization.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join("release", "kustomization.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Equal(t, string(expect), string(actual))
+	cleanup()
+}
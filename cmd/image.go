@@ -6,7 +6,6 @@ import (
 	"github.com/azunymous/easymodo/input"
 	"github.com/azunymous/easymodo/kustomization"
 	log "github.com/sirupsen/logrus"
-	"github.com/spf13/afero"
 	"path"
 	"path/filepath"
 	"regexp"
@@ -17,9 +16,10 @@ import (
 // imageCmd represents the version command
 var imageCmd = &cobra.Command{
 	Use:   "image [namespace]",
-	Short: "Change the version of an application via kustomize patch",
-	Long: `Create a kustomize overlay with a deployment patch changing the image of a container
-in a deployment.
+	Short: "Change the version of an application via kustomize images",
+	Long: `Create a kustomize overlay changing the image of the application with the kustomize images
+transformer, which also changes sidecars and init containers using the same image. With --patch,
+a deployment patch changes the image of the application container only.
 
 e.g easymodo modify image my-cool-app-production -i gcr.io/cool/my-app:v2.0.0
 
@@ -36,42 +36,52 @@ func init() {
 
 	imageCmd.Flags().StringVarP(ImageFlag(), "image", "i", "", "Image (required)")
 	_ = imageCmd.MarkFlagRequired("image")
+	imageCmd.Flags().BoolVar(ImagePatchFlag(), "patch", false, "Change the image with a patch of the application container instead of the kustomize images transformer")
 
 	imageCmd.Flags().StringVarP(OutputFlag(), "output", "o", "", "Output folder for kustomization files. Defaults to '<namespace folder name>-<version>'")
 }
 
 func newVersionCommand(c *cobra.Command, args []string) {
 	resourceFiles := fs.NewFileMap()
-	appName, appImage, appPort := input.GetBaseApp(fs.Get(), Directory())
+	base := input.GetBaseApp(fs.Get(), Directory())
+	appName, appImage := base.Name, base.Image
 	outputDir := Output()
 
 	namespace, nsDir := input.ValidateNamespaceOrSuffix(Suffix(), appName, args, c)
-	_, err := afero.ReadFile(fs.Get(), path.Join(Directory(), Context(), nsDir, "kustomization.yaml"))
+	overlay, err := kustomization.Read(fs.Get(), path.Join(Directory(), Context(), nsDir))
 	if err != nil {
 		log.Fatalf("Could not open %s kustomization.yaml file: %v.", nsDir, err)
 	}
+	if overlay.Namespace != "" {
+		namespace = overlay.Namespace
+	}
 
 	if outputDir == "" {
 		version := parseVersion(Image())
 		outputDir = path.Join(Context(), nsDir+"-"+version)
 	}
 
-	k := input.Kustomization{
+	k := &input.Kustomization{
 		Res:       []string{},
 		Patches:   []string{},
 		Config:    map[string][]string{},
 		Secrets:   map[string][]string{},
 		Namespace: namespace,
 	}
+	existing, err := kustomization.Read(fs.Get(), path.Join(Directory(), outputDir))
+	if err == nil {
+		log.Debugf("Changing existing kustomization.yaml in %s", outputDir)
+		k = existing
+	}
 
 	application := input.Application{
 		Name:          appName,
+		Kind:          base.Kind,
 		ContainerName: appName,
-		ContainerPort: appPort,
+		Ports:         base.Ports,
 		Namespace:     namespace,
 		Image:         Image(),
-		ConfigPath:    configPath,
-		Host:          Ingress(),
+		ConfigPath:    ConfigPath(),
 	}
 
 	if appImage == Image() {
@@ -81,11 +91,15 @@ func newVersionCommand(c *cobra.Command, args []string) {
 	relativeBasePath := filepath.Join("../", nsDir)
 	k.AddResource(relativeBasePath)
 
-	_ = kustomization.Generate("deployment-image-patch", kustomization.DeploymentImagePatch())(application, resourceFiles)
+	if ImagePatch() {
+		_ = kustomization.Generate(patchName(application, "image"), kustomization.DeploymentImagePatch())(application, resourceFiles)
 
-	k.AddPatch("deployment-image-patch.yaml")
+		k.AddPatch(patchName(application, "image") + ".yaml")
+	} else {
+		k.AddImage(input.NewImage(appImage, Image()))
+	}
 
-	kustomization.Create(&k, resourceFiles)
+	kustomization.Create(k, resourceFiles)
 	resourceFiles.WriteAll(Directory(), outputDir)
 	abs, _ := filepath.Abs(path.Join(Directory(), outputDir))
 	_, _ = fmt.Fprintln(w, abs)
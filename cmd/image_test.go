@@ -104,6 +104,7 @@ func TestCreatesImageOverlayPatch(t *testing.T) {
 		"image",
 		"app-dev",
 		"-i app:v1.0.0",
+		"--patch",
 	})
 	_ = cmd.Execute()
 	println(buf.String())
@@ -226,3 +227,74 @@ func TestCreatesImageOverlayKustomizationWithContext(t *testing.T) {
 	assert.YAMLEq(t, string(expect), string(actual))
 	cleanup()
 }
+
+func TestCreatesImageOverlayPatchKustomization(t *testing.T) {
+	cmd, buf, err := setUpImageCommand()
+	cmd.SetArgs([]string{
+		"modify",
+		"image",
+		"app-dev",
+		"-i app:v1.0.0",
+		"--patch",
+	})
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, "app-dev-v1.0.0", "kustomization.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("different-image-patch", "app-dev-v1.0.0", "kustomization.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesImageOverlayKustomizationForNewRepositoryAndDigest(t *testing.T) {
+	cmd, buf, err := setUpImageCommand()
+	cmd.SetArgs([]string{
+		"modify",
+		"image",
+		"app-dev",
+		"-i", "gcr.io/dev/app@sha256:4d2c1c5a1b3f0e2b3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b",
+		"-o", "app-dev-digest",
+	})
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, "app-dev-digest", "kustomization.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("different-image-digest", "app-dev-digest", "kustomization.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	exists, _ := afero.Exists(fs.Get(), path.Join(platformDirDefault, "app-dev-digest", "deployment-image-patch.yaml"))
+	assert.False(t, exists)
+	cleanup()
+}
+
+func TestChangesExistingImageOverlayKustomizationInPlace(t *testing.T) {
+	cmd, buf, err := setUpImageCommand()
+	cmd.SetArgs([]string{
+		"modify",
+		"image",
+		"app-dev",
+		"-i", "app:v1.0.0",
+		"-o", "app-dev-release",
+	})
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, "app-dev-release", "kustomization.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("different-image-in-place", "app-dev-release", "kustomization.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Equal(t, string(expect), string(actual))
+	cleanup()
+}
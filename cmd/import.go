@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// importCmd represents the import command
+var importCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import existing application definitions",
+	Long: `Import applications described for other tools as kustomize bases and overlays.
+For example:
+easymodo import compose docker-compose.yml`,
+}
+
+func init() {
+	rootCmd.AddCommand(importCmd)
+}
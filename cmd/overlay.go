@@ -5,9 +5,13 @@ import (
 	"github.com/azunymous/easymodo/input"
 	"github.com/azunymous/easymodo/kustomization"
 	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"path"
 	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
 )
 
 // overlayCmd represents the overlay command for creating a kustomize overlay
@@ -21,50 +25,95 @@ kustomization options via flags`,
 	Args: cobra.MaximumNArgs(1),
 }
 
-var configPath string
-
 func init() {
 	createCmd.AddCommand(overlayCmd)
 
 	overlayCmd.PersistentFlags().StringToStringVarP(ConfigFilesFlag(), "configFile", "c", nil, "Configuration filename and file for generating config maps")
-	overlayCmd.PersistentFlags().StringVarP(&configPath, "configPath", "p", "/config/", "Configuration folder for mounting config map contents")
+	overlayCmd.PersistentFlags().StringVarP(ConfigPathFlag(), "configPath", "p", "", "Configuration folder for mounting config map contents (default /config/)")
 
 	overlayCmd.PersistentFlags().StringToStringVarP(SecretEnvsFlag(), "secretEnv", "e", nil, "Secret .env filename and env file for generating secrets")
+	overlayCmd.Flags().StringArrayVar(AgeRecipientsFlag(), "age-recipient", []string{}, "age public key to encrypt secret env files for with SOPS. Can be repeated")
+	overlayCmd.Flags().StringVar(AgeKeyFileFlag(), "age-key-file", "", "age key file to encrypt secret env files for with SOPS")
+	overlayCmd.Flags().StringVar(SealedSecretCertFlag(), "sealed-secret-cert", "", "Public certificate of the sealed secrets controller for sealing secret env files into a SealedSecret with kubeseal")
+	overlayCmd.Flags().StringArrayVar(ExternalSecretsFlag(), "external-secret", []string{}, "Secret key fetched from the secret store by an ExternalSecret instead of a secret env file, as KEY=remote-key[#property] e.g DB_PASSWORD=prod/db#password. Can be repeated")
+	overlayCmd.Flags().StringVar(SecretStoreFlag(), "secret-store", "", "Name of the secret store for external secrets")
+	overlayCmd.Flags().StringVar(SecretStoreKindFlag(), "secret-store-kind", "SecretStore", "Kind of the secret store for external secrets: SecretStore or ClusterSecretStore")
+	overlayCmd.Flags().StringVar(RefreshIntervalFlag(), "refresh-interval", "1h", "Interval for refreshing external secrets from the secret store")
+	overlayCmd.Flags().StringToStringVar(SecretFilesFlag(), "secretFile", map[string]string{}, "Secret file name and path to the file for generating a secret mounted as files e.g tls.key=./certs/tls.key")
+	overlayCmd.Flags().StringVar(SecretPathFlag(), "secretPath", "", "Folder for mounting secret files (default /secrets/)")
+	overlayCmd.Flags().StringArrayVar(EnvFlag(), "env", []string{}, "Environment variable as KEY=VALUE for generating a config map exposed to the container. Can be repeated")
+	overlayCmd.Flags().StringArrayVar(EnvFilesFlag(), "env-file", []string{}, "Path to a .env file of non secret environment variables for generating a config map exposed to the container")
+
+	addVolumeFlags(overlayCmd)
+	addContainerFlags(overlayCmd)
+	overlayCmd.Flags().StringVar(ContainerFlag(), "container", "", "Container targeted by the requests, limits, probe, config, secret, secret file, env and volume patches. Defaults to the application container")
 
-	overlayCmd.Flags().StringVar(IngressFlag(), "ingress", "", "Enable ingress resource generation with given host")
+	overlayCmd.Flags().StringVar(ServiceTypeFlag(), "service-type", "", "Patch the service type: ClusterIP, NodePort or LoadBalancer")
+	overlayCmd.Flags().StringToStringVar(ServiceAnnotationsFlag(), "service-annotation", map[string]string{}, "Service annotations e.g for an internal load balancer. For example, 'service.beta.kubernetes.io/aws-load-balancer-internal=true'")
+	addIngressFlags(overlayCmd)
 	overlayCmd.Flags().IntVarP(ReplicasFlag(), "replicas", "r", 1, "Enable ingress resource generation with given host")
+	overlayCmd.Flags().StringToStringVar(AutoscaleFlag(), "autoscale", map[string]string{}, "Create a HorizontalPodAutoscaler instead of a fixed replica count. For example, 'min=2,max=10,cpu=70,memory=80'")
+	overlayCmd.Flags().StringToStringVar(PdbFlag(), "pdb", map[string]string{}, "Create a PodDisruptionBudget. For example, 'minAvailable=1' or 'maxUnavailable=25%'")
+	overlayCmd.Flags().BoolVar(NetworkPolicyFlag(), "network-policy", false, "Create a NetworkPolicy denying all ingress to the application except from allowed namespaces and applications")
+	overlayCmd.Flags().StringArrayVar(AllowFromNamespacesFlag(), "allow-from-namespace", []string{}, "Namespace allowed to reach the application e.g ingress-nginx. Enables the network policy")
+	overlayCmd.Flags().StringArrayVar(AllowFromAppsFlag(), "allow-from-app", []string{}, "Application allowed to reach the application, as <app> or <namespace>/<app>. Enables the network policy")
+	overlayCmd.Flags().BoolVar(RestrictEgressFlag(), "restrict-egress", false, "Deny egress from the application except DNS and allowed namespaces and applications. Enables the network policy")
+	overlayCmd.Flags().StringArrayVar(AllowToNamespacesFlag(), "allow-to-namespace", []string{}, "Namespace the application is allowed to reach. Restricts egress")
+	overlayCmd.Flags().StringArrayVar(AllowToAppsFlag(), "allow-to-app", []string{}, "Application the application is allowed to reach, as <app> or <namespace>/<app>. Restricts egress")
+	overlayCmd.Flags().StringArrayVar(GrantsFlag(), "grant", []string{}, "Grant verbs on resources to the application's service account through a Role. For example, 'pods,deployments.apps=get,list,watch'")
+	overlayCmd.Flags().StringToStringVar(NodeSelectorFlag(), "node-selector", map[string]string{}, "Node labels the pods are scheduled on. For example, 'cloud.google.com/gke-nodepool=prod'")
+	overlayCmd.Flags().StringArrayVar(TolerationsFlag(), "toleration", []string{}, "Taint tolerated by the pods as key[=value][:effect] e.g dedicated=prod:NoSchedule. Can be repeated")
+	overlayCmd.Flags().StringVar(AntiAffinityFlag(), "anti-affinity", "", "Keep the pods apart with a pod anti-affinity on the app label as required|preferred[=topologyKey]. The topology key defaults to kubernetes.io/hostname")
+	overlayCmd.Flags().StringArrayVar(TopologySpreadFlag(), "topology-spread", []string{}, "Spread the pods across a topology as topologyKey[,maxSkew=1][,whenUnsatisfiable=DoNotSchedule] e.g topology.kubernetes.io/zone. Can be repeated")
+	overlayCmd.Flags().StringVar(PriorityClassFlag(), "priority-class", "", "Priority class name of the pods")
 	overlayCmd.Flags().StringToStringVar(LimitsFlag(), "limits", map[string]string{}, "The resource requirement limits for this container.  For example, 'cpu=200m,memory=512Mi'")
 	overlayCmd.Flags().StringToStringVar(RequestsFlag(), "requests", map[string]string{}, "The resource requirement requests for this container.  For example, 'cpu=200m,memory=512Mi'")
 
+	addProbeFlags(overlayCmd)
+	addCommonFlags(overlayCmd)
+	overlayCmd.Flags().StringToStringVar(SecurityContextFlag(), "security-context", map[string]string{}, "Patch the user, group and filesystem group the pod runs as. For example, 'runAsUser=1000,runAsGroup=1000,fsGroup=2000'")
+
 	overlayCmd.PersistentFlags().StringVarP(SuffixFlag(), "suffix", "s", "", "Suffix to use for namespace for overlay")
 	overlayCmd.Flags().BoolVarP(NamespaceResourceFlag(), "namespace-resource", "n", false, "Create namespace resource")
 }
 
 func newOverlayCommand(c *cobra.Command, args []string) {
 	resourceFiles := fs.NewFileMap()
-	appName, _, appPort := input.GetBaseApp(fs.Get(), Directory())
+	base := input.GetBaseApp(fs.Get(), Directory())
+	appName := base.Name
 
 	namespace, nsDir := input.ValidateNamespaceOrSuffix(Suffix(), appName, args, c)
 	validateContainerResources(Requests(), "Requests")
 	validateContainerResources(Limits(), "Limits")
 
-	k := input.Kustomization{
-		Res:       []string{},
-		Patches:   []string{},
-		Config:    map[string][]string{},
-		Secrets:   map[string][]string{},
-		Namespace: namespace,
+	k := &input.Kustomization{
+		Res:     []string{},
+		Patches: []string{},
+		Config:  map[string][]string{},
+		Secrets: map[string][]string{},
+	}
+	previous := &input.Kustomization{}
+	overlayDir := path.Join(Directory(), Context(), nsDir)
+	if existing, err := kustomization.Read(fs.Get(), overlayDir); err == nil {
+		log.Infof("Merging into existing overlay %s", path.Join(Context(), nsDir))
+		k, previous = existing, existing.Copy()
 	}
+	k.Namespace = namespace
+	k.AddCommon(Labels(), Annotations())
 
 	application := input.Application{
-		Name:          appName,
-		ContainerName: appName,
-		ContainerPort: appPort,
-		Namespace:     namespace,
-		ConfigPath:    configPath,
-		Host:          Ingress(),
-		Replicas:      Replicas(),
+		Name:           appName,
+		Kind:           base.Kind,
+		Stateful:       base.Stateful,
+		ContainerName:  containerName(base),
+		Ports:          base.Ports,
+		ServiceType:    base.ServiceType,
+		Namespace:      namespace,
+		Replicas:       Replicas(),
+		ServiceAccount: base.ServiceAccount,
 	}
+	application.ConfigPath = mountPath(application, overlayDir, "config", "config", ConfigPath(), "/config/")
+	application.SecretPath = mountPath(application, overlayDir, "secret-file", "secret-files", SecretPath(), "/secrets/")
 
 	k.AddResource(relativeBasePath())
 
@@ -77,11 +126,22 @@ func newOverlayCommand(c *cobra.Command, args []string) {
 		}
 	}
 
-	addContainerResourceGenerator(application, resourceFiles, &k)
-	addConfigGenerator(application, resourceFiles, &k, appName)
-	addSecretGenerator(application, resourceFiles, &k, appName)
+	addContainerGenerator(application, base, resourceFiles, k)
+	addContainerResourceGenerator(application, resourceFiles, k, overlayDir)
+	addProbeGenerator(targetExisting(application, overlayDir, "probe"), resourceFiles, k)
+	addSecurityContextGenerator(application, resourceFiles, k)
+	addSchedulingGenerator(application, resourceFiles, k)
+	addConfigGenerator(targetExisting(application, overlayDir, "config"), resourceFiles, k, appName)
+	addSecretGenerator(targetExisting(application, overlayDir, "secret"), resourceFiles, k, appName)
+	addSecretFileGenerator(targetExisting(application, overlayDir, "secret-file"), resourceFiles, k, appName)
+	addEnvGenerator(targetExisting(application, overlayDir, "env"), resourceFiles, k, appName, overlayDir)
+	addVolumeGenerator(targetExisting(application, overlayDir, "volume"), base.Volumes, resourceFiles, k)
+	addServicePatchGenerator(application, resourceFiles, k)
 
-	if Ingress() != "" {
+	if len(Ingress()) > 0 && application.Batch() {
+		log.Warnf("Skipping ingress as a %s has no service", application.Kind)
+	} else if len(Ingress()) > 0 {
+		application.Ingress = ingress(application.Ports)
 		err := kustomization.Generate("ingress", kustomization.Ingress())(application, resourceFiles)
 		if err != nil {
 			log.Warnf("Could not create ingress: %v", err)
@@ -90,51 +150,373 @@ func newOverlayCommand(c *cobra.Command, args []string) {
 		}
 	}
 
-	if Replicas() != 1 {
-		err := kustomization.Generate("deployment-replica-patch", kustomization.DeploymentReplicaPatch())(application, resourceFiles)
+	addAutoscalingGenerator(application, resourceFiles, k)
+	addDisruptionBudgetGenerator(application, resourceFiles, k, overlayDir)
+	addNetworkPolicyGenerator(application, resourceFiles, k)
+	addRoleGenerator(application, resourceFiles, k)
+
+	if Replicas() != 1 && !input.IsScalable(application.Kind) {
+		log.Warnf("Skipping replica patch as a %s has no replica count", application.Kind)
+	} else if Replicas() != 1 {
+		if contains(k.Res, "hpa.yaml") {
+			log.Fatalf("Cannot set replicas to %d as the overlay has a HorizontalPodAutoscaler in hpa.yaml that would conflict with the replica patch", Replicas())
+		}
+		err := kustomization.Generate(patchName(application, "replica"), kustomization.DeploymentReplicaPatch())(application, resourceFiles)
 		if err != nil {
 			log.Fatalf("Could not create replica patch %v", err)
 		} else {
-			k.AddPatch("deployment-replica-patch.yaml")
+			k.AddPatch(patchName(application, "replica") + ".yaml")
 		}
 	}
 
-	kustomization.Create(&k, resourceFiles)
+	mergeExisting(previous, resourceFiles, overlayDir)
+	kustomization.Create(k, resourceFiles)
+	reportChanges(k.Changes(previous), resourceFiles.Changed(Directory(), path.Join(Context(), nsDir)))
 	resourceFiles.WriteAll(Directory(), path.Join(Context(), nsDir))
+}
+
+// mergeExisting merges the resources and patches created again for an existing overlay into the files of
+// the overlay, as the flags only hold what is being added or set and the overlay keeps the rest
+func mergeExisting(previous *input.Kustomization, resourceFiles fs.Files, overlayDir string) {
+	for _, file := range resourceFiles.GetFilenames() {
+		if !contains(previous.Res, file) && !contains(previous.Patches, file) {
+			continue
+		}
+		existing, err := afero.ReadFile(fs.Get(), path.Join(overlayDir, file))
+		if err != nil {
+			continue
+		}
+		merged, err := kustomization.MergeManifest(string(existing), resourceFiles.Content(file))
+		if err != nil {
+			log.Fatalf("Could not merge %s into the existing overlay: %v", file, err)
+		}
+		resourceFiles.Add(file, merged)
+	}
+}
+
+// reportChanges logs the changes to the kustomization of the overlay and the files that are written
+func reportChanges(changes []string, files []string) {
+	for _, change := range changes {
+		log.Infof("Overlay %s", change)
+	}
+	for _, file := range files {
+		if file != "kustomization.yaml" {
+			log.Infof("Wrote %s", file)
+		}
+	}
+	if len(changes) == 0 && len(files) == 0 {
+		log.Infof("Overlay is up to date")
+	}
+}
+
+// containerName returns the name of the container targeted by the overlay patches, which is the
+// application container unless set with --container
+func containerName(base input.Application) string {
+	if Container() == "" || Container() == base.Name {
+		return base.Name
+	}
+	if _, ok := input.FindContainer(base.InitContainers, Container()); ok {
+		log.Fatalf("Cannot target init container %s, patches can only target the application container or sidecars", Container())
+	}
+	sidecars := base.Sidecars
+	for _, definition := range Sidecars() {
+		if c, err := input.ParseContainer(definition); err == nil {
+			sidecars = append(sidecars, c)
+		}
+	}
+	if _, ok := input.FindContainer(sidecars, Container()); !ok {
+		log.Fatalf("Cannot target container %s as it is not in the base or added as a sidecar", Container())
+	}
+	return Container()
+}
 
+// addContainerGenerator adds the init and sidecar containers given to the overlay with a patch, using
+// the security profile of the base
+func addContainerGenerator(application input.Application, base input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+	if len(InitContainers()) == 0 && len(Sidecars()) == 0 {
+		return
+	}
+	application.ContainerName = base.Name
+	application.InitContainers, application.Sidecars = base.InitContainers, base.Sidecars
+	addContainers(&application)
+	application.InitContainers = application.InitContainers[len(base.InitContainers):]
+	application.Sidecars = application.Sidecars[len(base.Sidecars):]
+	application.Security = base.Security
+
+	err := kustomization.Generate(patchName(application, "containers"), kustomization.DeploymentContainersPatch())(application, resourceFiles)
+	if err != nil {
+		log.Fatalf("Could not create containers patch: %v", err)
+	}
+	k.AddPatch(patchName(application, "containers") + ".yaml")
 }
 
-func addContainerResourceGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+func addContainerResourceGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization, overlayDir string) {
 	if len(Requests()) > 0 {
+		application := targetExisting(application, overlayDir, "requests")
 		setContainerResource(Requests(), "cpu", &application.CpuRequests)
 		setContainerResource(Requests(), "memory", &application.MemoryRequests)
-		err := kustomization.Generate("deployment-requests-patch", kustomization.DeploymentRequestsPatch())(application, resourceFiles)
+		err := kustomization.Generate(patchName(application, "requests"), kustomization.DeploymentRequestsPatch())(application, resourceFiles)
 		if err != nil {
 			log.Warnf("Could not create request patch: %v", err)
 		} else {
-			k.AddPatch("deployment-requests-patch.yaml")
+			k.AddPatch(patchName(application, "requests") + ".yaml")
 		}
 	}
 	if len(Limits()) > 0 {
+		application := targetExisting(application, overlayDir, "limits")
 		setContainerResource(Limits(), "cpu", &application.CpuLimits)
 		setContainerResource(Limits(), "memory", &application.MemoryLimits)
-		err := kustomization.Generate("deployment-limits-patch", kustomization.DeploymentLimitsPatch())(application, resourceFiles)
+		err := kustomization.Generate(patchName(application, "limits"), kustomization.DeploymentLimitsPatch())(application, resourceFiles)
 		if err != nil {
 			log.Warnf("Could not create limits patch: %v", err)
 		} else {
-			k.AddPatch("deployment-limits-patch.yaml")
+			k.AddPatch(patchName(application, "limits") + ".yaml")
+		}
+	}
+}
+
+// addVolumeGenerator mounts the volumes added by the overlay, and patches the size and storage class of
+// claims from the base
+func addVolumeGenerator(application input.Application, baseVolumes []input.Volume, resourceFiles fs.Files, k *input.Kustomization) {
+	if len(Volumes()) == 0 && len(EmptyDirs()) == 0 {
+		return
+	}
+	var added, overridden []input.Volume
+	for _, v := range volumes(application.Kind, baseVolumes) {
+		if v.Claim && application.Stateful {
+			log.Fatalf("Cannot add or change volume claim %s in an overlay as the volume claim templates of a StatefulSet cannot be changed. Add it to the base instead", v.Name)
+		}
+		if _, ok := input.FindVolume(baseVolumes, v.Name); ok {
+			overridden = append(overridden, v)
+		} else {
+			added = append(added, v)
+		}
+	}
+
+	if len(added) > 0 {
+		application.Volumes = added
+		if len(application.Claims()) > 0 {
+			err := kustomization.Generate("pvc", kustomization.PersistentVolumeClaims())(application, resourceFiles)
+			if err != nil {
+				log.Fatalf("Could not create persistent volume claims: %v", err)
+			}
+			k.AddResource("pvc.yaml")
+		}
+		err := kustomization.Generate(patchName(application, "volume"), kustomization.DeploymentVolumePatch())(application, resourceFiles)
+		if err != nil {
+			log.Fatalf("Could not create volume patch: %v", err)
+		}
+		k.AddPatch(patchName(application, "volume") + ".yaml")
+	}
+	if len(overridden) > 0 {
+		application.Volumes = overridden
+		err := kustomization.Generate("pvc-patch", kustomization.PersistentVolumeClaimPatch())(application, resourceFiles)
+		if err != nil {
+			log.Fatalf("Could not create persistent volume claim patch: %v", err)
+		}
+		k.AddPatch("pvc-patch.yaml")
+	}
+}
+
+func addServicePatchGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+	if ServiceType() == "" && len(ServiceAnnotations()) == 0 {
+		return
+	}
+	if application.Batch() {
+		log.Warnf("Skipping service patch as a %s has no service", application.Kind)
+		return
+	}
+	patch := application
+	patch.ServiceType = ""
+	if ServiceType() != "" {
+		serviceType, err := input.ParseServiceType(ServiceType())
+		if err != nil {
+			log.Fatalf("Service type flag is not correctly defined: %v", err)
+		}
+		if serviceType == input.ServiceHeadless || application.Headless() {
+			log.Fatalf("Cannot patch the service type to %s as the cluster IP of the base service cannot be changed", serviceType)
+		}
+		patch.ServiceType = serviceType
+	}
+	patch.ServiceAnnotations = ServiceAnnotations()
+
+	err := kustomization.Generate("service-patch", kustomization.ServicePatch())(patch, resourceFiles)
+	if err != nil {
+		log.Fatalf("Could not create service patch: %v", err)
+	}
+	k.AddPatch("service-patch.yaml")
+}
+
+func addAutoscalingGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+	autoscaling, err := input.ParseAutoscaling(Autoscale())
+	if err != nil {
+		log.Fatalf("Autoscale flag is not correctly defined: %v", err)
+	}
+	if autoscaling == nil {
+		return
+	}
+	if !input.IsScalable(application.Kind) {
+		log.Fatalf("Cannot autoscale a %s", application.Kind)
+	}
+	if Replicas() != 1 {
+		log.Fatalf("Cannot set replicas to %d when autoscaling as the replica patch would conflict with the HorizontalPodAutoscaler", Replicas())
+	}
+	if replicaPatch := patchName(application, "replica") + ".yaml"; contains(k.Patches, replicaPatch) {
+		log.Fatalf("Cannot autoscale as the overlay has the replica patch %s that would conflict with the HorizontalPodAutoscaler. Remove it from the overlay first", replicaPatch)
+	}
+
+	application.Autoscaling = autoscaling
+	err = kustomization.Generate("hpa", kustomization.HorizontalPodAutoscaler())(application, resourceFiles)
+	if err != nil {
+		log.Fatalf("Could not create horizontal pod autoscaler: %v", err)
+	}
+	k.AddResource("hpa.yaml")
+}
+
+func addDisruptionBudgetGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization, overlayDir string) {
+	budget, err := input.ParseDisruptionBudget(Pdb())
+	if err != nil {
+		log.Fatalf("PDB flag is not correctly defined: %v", err)
+	}
+	if budget == nil {
+		return
+	}
+	if !input.IsScalable(application.Kind) {
+		log.Fatalf("Cannot create a pod disruption budget for a %s", application.Kind)
+	}
+
+	replicas := Replicas()
+	if replicas == 1 {
+		replicas = existingReplicas(application, k, overlayDir)
+	}
+	if autoscaling, _ := input.ParseAutoscaling(Autoscale()); autoscaling != nil {
+		replicas = autoscaling.MinReplicas
+	}
+	if err := budget.Check(replicas); err != nil {
+		log.Fatalf("Cannot create pod disruption budget: %v. Increase the replicas or loosen the budget", err)
+	}
+
+	application.DisruptionBudget = budget
+	err = kustomization.Generate("pdb", kustomization.PodDisruptionBudget())(application, resourceFiles)
+	if err != nil {
+		log.Fatalf("Could not create pod disruption budget: %v", err)
+	}
+	k.AddResource("pdb.yaml")
+}
+
+func addNetworkPolicyGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+	restrictEgress := RestrictEgress() || len(AllowToNamespaces()) > 0 || len(AllowToApps()) > 0
+	if !NetworkPolicy() && !restrictEgress && len(AllowFromNamespaces()) == 0 && len(AllowFromApps()) == 0 {
+		return
+	}
+
+	ingressPeers, err := input.ParsePeers(AllowFromNamespaces(), AllowFromApps())
+	if err != nil {
+		log.Fatalf("Network policy ingress flags are not correctly defined: %v", err)
+	}
+	egressPeers, err := input.ParsePeers(AllowToNamespaces(), AllowToApps())
+	if err != nil {
+		log.Fatalf("Network policy egress flags are not correctly defined: %v", err)
+	}
+
+	application.NetworkPolicy = &input.NetworkPolicy{
+		IngressPeers:   ingressPeers,
+		RestrictEgress: restrictEgress,
+		EgressPeers:    egressPeers,
+	}
+	err = kustomization.Generate("networkpolicy", kustomization.NetworkPolicy())(application, resourceFiles)
+	if err != nil {
+		log.Fatalf("Could not create network policy: %v", err)
+	}
+	k.AddResource("networkpolicy.yaml")
+}
+
+func addRoleGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+	if len(Grants()) == 0 {
+		return
+	}
+	if application.ServiceAccount == "" {
+		log.Fatalf("Cannot grant permissions as the base has no service account. Create the base with --service-account")
+	}
+
+	for _, grant := range Grants() {
+		rules, err := input.ParseGrant(grant)
+		if err != nil {
+			log.Fatalf("Grant flag is not correctly defined: %v", err)
+		}
+		application.Rules = append(application.Rules, rules...)
+	}
+
+	for name, template := range map[string]*template.Template{"role": kustomization.Role(), "rolebinding": kustomization.RoleBinding()} {
+		err := kustomization.Generate(name, template)(application, resourceFiles)
+		if err != nil {
+			log.Fatalf("Could not create %s: %v", name, err)
 		}
 	}
+	k.AddResource("role.yaml")
+	k.AddResource("rolebinding.yaml")
+}
+
+func addProbeGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+	setProbes(&application, false)
+	if application.LivenessProbe == nil && application.ReadinessProbe == nil && application.StartupProbe == nil {
+		return
+	}
+	err := kustomization.Generate(patchName(application, "probe"), kustomization.DeploymentProbePatch())(application, resourceFiles)
+	if err != nil {
+		log.Warnf("Could not create probe patch: %v", err)
+	} else {
+		k.AddPatch(patchName(application, "probe") + ".yaml")
+	}
+}
+
+func addSecurityContextGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+	security, err := input.ParseSecurityContext(SecurityContext())
+	if err != nil {
+		log.Fatalf("Security context flag is not correctly defined: %v", err)
+	}
+	if security == nil {
+		return
+	}
+	application.Security = security
+	err = kustomization.Generate(patchName(application, "security"), kustomization.DeploymentSecurityPatch())(application, resourceFiles)
+	if err != nil {
+		log.Fatalf("Could not create security context patch: %v", err)
+	}
+	k.AddPatch(patchName(application, "security") + ".yaml")
+}
+
+func addSchedulingGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+	scheduling, err := input.ParseScheduling(NodeSelector(), Tolerations(), AntiAffinity(), TopologySpread(), PriorityClass())
+	if err != nil {
+		log.Fatalf("Scheduling flags are not correctly defined: %v", err)
+	}
+	if scheduling == nil {
+		return
+	}
+	if (scheduling.AntiAffinity != nil || len(scheduling.TopologySpread) > 0) && application.Kind == input.KindDaemonSet {
+		log.Warnf("Anti-affinity and topology spread have no effect on a DaemonSet, which runs a pod on every node")
+	}
+	if a := scheduling.AntiAffinity; a != nil && a.Required() && a.TopologyKey != "kubernetes.io/hostname" {
+		log.Warnf("Required anti-affinity on %s schedules at most one pod for each of its values", a.TopologyKey)
+	}
+
+	application.Scheduling = scheduling
+	err = kustomization.Generate(patchName(application, "scheduling"), kustomization.DeploymentSchedulingPatch())(application, resourceFiles)
+	if err != nil {
+		log.Fatalf("Could not create scheduling patch: %v", err)
+	}
+	k.AddPatch(patchName(application, "scheduling") + ".yaml")
 }
 
 func addConfigGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization, appName string) {
 	if len(ConfigFiles()) > 0 {
-		err := kustomization.Generate("deployment-config-patch", kustomization.DeploymentConfigPatch())(application, resourceFiles)
+		err := kustomization.Generate(patchName(application, "config"), kustomization.DeploymentConfigPatch())(application, resourceFiles)
 		if err != nil {
 			log.Fatalf("could not create deployment patch with given config file: %v", err)
 		}
 
-		k.AddPatch("deployment-config-patch.yaml")
+		k.AddPatch(patchName(application, "config") + ".yaml")
 
 		for fileName, content := range ConfigFiles() {
 			resourceFiles.Add(fileName, content)
@@ -143,22 +525,211 @@ func addConfigGenerator(application input.Application, resourceFiles fs.Files, k
 	}
 }
 
+// addSecretGenerator generates the application secret exposed to the container from secret env
+// files, sealed with kubeseal when a certificate is given, or from a secret store with an
+// ExternalSecret so that no secret material is kept in the overlay.
 func addSecretGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization, appName string) {
-	if len(SecretEnvs()) > 0 {
-		err := kustomization.Generate("deployment-secret-patch", kustomization.DeploymentSecretPatch())(application, resourceFiles)
-		if err != nil {
-			log.Fatalf("could not create deployment patch with given secret file: %v", err)
+	if len(SecretEnvs()) == 0 && len(ExternalSecrets()) == 0 {
+		if SealedSecretCert() != "" {
+			log.Fatalf("Cannot create a sealed secret without secret env files. Set them with -e")
 		}
+		return
+	}
+	if len(SecretEnvs()) > 0 && len(ExternalSecrets()) > 0 {
+		log.Fatalf("Cannot set both secret env files and external secrets as both create the %s-secret secret", appName)
+	}
+	if SealedSecretCert() != "" && (encryptSecrets() || len(ExternalSecrets()) > 0) {
+		log.Fatalf("Sealed secrets cannot be combined with encrypted secret env files or external secrets")
+	}
+
+	err := kustomization.Generate(patchName(application, "secret"), kustomization.DeploymentSecretPatch())(application, resourceFiles)
+	if err != nil {
+		log.Fatalf("could not create deployment patch with given secret file: %v", err)
+	}
 
-		k.AddPatch("deployment-secret-patch.yaml")
+	k.AddPatch(patchName(application, "secret") + ".yaml")
 
+	switch {
+	case len(ExternalSecrets()) > 0:
+		addExternalSecret(application, resourceFiles, k)
+	case SealedSecretCert() != "":
+		addSealedSecret(application, resourceFiles, k)
+	default:
 		for fileName, content := range SecretEnvs() {
+			if encryptSecrets() {
+				content = encryptSecretEnv(fileName, content)
+			}
 			resourceFiles.Add(fileName, content)
 			k.AddSecret(appName+"-secret", fileName)
 		}
 	}
 }
 
+func addExternalSecret(application input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+	externalSecret, err := input.ParseExternalSecret(SecretStore(), SecretStoreKind(), RefreshInterval(), ExternalSecrets())
+	if err != nil {
+		log.Fatalf("External secret flags are not correctly defined: %v", err)
+	}
+	application.ExternalSecret = externalSecret
+	err = kustomization.Generate("externalsecret", kustomization.ExternalSecret())(application, resourceFiles)
+	if err != nil {
+		log.Fatalf("Could not create external secret: %v", err)
+	}
+	k.AddResource("externalsecret.yaml")
+}
+
+// addSealedSecret seals the variables of the secret env files into a SealedSecret for the overlay
+// namespace, which only the sealed secrets controller in the cluster can decrypt
+func addSealedSecret(application input.Application, resourceFiles fs.Files, k *input.Kustomization) {
+	fileNames := make([]string, 0, len(SecretEnvs()))
+	for fileName := range SecretEnvs() {
+		fileNames = append(fileNames, fileName)
+	}
+	sort.Strings(fileNames)
+
+	keys := map[string]string{}
+	for _, fileName := range fileNames {
+		env, err := input.ParseEnvFile(SecretEnvs()[fileName])
+		if err != nil {
+			log.Fatalf("Secret env file %s is not correctly defined: %v", fileName, err)
+		}
+		for _, e := range env {
+			if previous, ok := keys[e.Name]; ok {
+				log.Fatalf("Secret %s is set by both %s and %s", e.Name, previous, fileName)
+			}
+			keys[e.Name] = fileName
+			application.Env = append(application.Env, e)
+		}
+	}
+
+	secret := strings.Builder{}
+	if err := kustomization.Secret().Execute(&secret, application); err != nil {
+		log.Fatalf("Could not create secret for sealing: %v", err)
+	}
+	resourceFiles.Add("sealedsecret.yaml", sealSecret(secret.String(), SealedSecretCert()))
+	k.AddResource("sealedsecret.yaml")
+}
+
+func addSecretFileGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization, appName string) {
+	if len(SecretFiles()) == 0 {
+		return
+	}
+	if path.Clean(application.SecretPath) == path.Clean(application.ConfigPath) && len(ConfigFiles()) > 0 {
+		log.Fatalf("Secret files cannot be mounted at the config path %s", application.ConfigPath)
+	}
+
+	names := make([]string, 0, len(SecretFiles()))
+	for name := range SecretFiles() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		content, err := afero.ReadFile(fs.Get(), SecretFiles()[name])
+		if err != nil {
+			log.Fatalf("Could not read secret file %s: %v", SecretFiles()[name], err)
+		}
+		resourceFiles.Add(name, string(content))
+		k.AddSecretFile(appName+"-secret-files", name)
+	}
+
+	err := kustomization.Generate(patchName(application, "secret-file"), kustomization.DeploymentSecretFilePatch())(application, resourceFiles)
+	if err != nil {
+		log.Fatalf("could not create deployment patch with given secret files: %v", err)
+	}
+	k.AddPatch(patchName(application, "secret-file") + ".yaml")
+}
+
+// addEnvGenerator generates a config map from the env flags, exposing each variable to the container
+// through an env entry. Entries are used over envFrom, as envFrom lists are replaced rather than merged
+// by other patches such as the secret patch.
+func addEnvGenerator(application input.Application, resourceFiles fs.Files, k *input.Kustomization, appName string, overlayDir string) {
+	if len(Env()) == 0 && len(EnvFiles()) == 0 {
+		return
+	}
+	existingFiles, existingLiterals := k.ConfigEnvs[appName+"-env"], k.ConfigLiterals[appName+"-env"]
+
+	names := map[string]string{}
+	addEnv := func(env input.EnvVar, source string) {
+		if previous, ok := names[env.Name]; ok {
+			log.Fatalf("Environment variable %s is set by both %s and %s", env.Name, previous, source)
+		}
+		names[env.Name] = source
+		application.Env = append(application.Env, env)
+	}
+
+	for _, envFile := range EnvFiles() {
+		content, err := afero.ReadFile(fs.Get(), envFile)
+		if err != nil {
+			log.Fatalf("Could not read env file %s: %v", envFile, err)
+		}
+		env, err := input.ParseEnvFile(string(content))
+		if err != nil {
+			log.Fatalf("Env file %s is not correctly defined: %v", envFile, err)
+		}
+		for _, e := range env {
+			addEnv(e, envFile)
+		}
+		resourceFiles.Add(path.Base(envFile), string(content))
+		k.AddConfigEnv(appName+"-env", path.Base(envFile))
+	}
+	for _, definition := range Env() {
+		env, err := input.ParseEnv(definition)
+		if err != nil {
+			log.Fatalf("Env flag is not correctly defined: %v", err)
+		}
+		addEnv(env, "--env")
+		k.AddConfigLiteral(appName+"-env", definition)
+	}
+	addExistingEnv(&application, names, existingFiles, existingLiterals, overlayDir)
+
+	err := kustomization.Generate(patchName(application, "env"), kustomization.DeploymentEnvPatch())(application, resourceFiles)
+	if err != nil {
+		log.Fatalf("Could not create env patch: %v", err)
+	}
+	k.AddPatch(patchName(application, "env") + ".yaml")
+}
+
+// addExistingEnv adds the variables already in the env config map of an existing overlay to the
+// application, so that the env patch keeps exposing them. Variables and env files set again are skipped.
+func addExistingEnv(application *input.Application, names map[string]string, envFiles []string, literals []string, overlayDir string) {
+	replaced := map[string]bool{}
+	for _, envFile := range EnvFiles() {
+		replaced[path.Base(envFile)] = true
+	}
+	for _, envFile := range envFiles {
+		if replaced[envFile] {
+			continue
+		}
+		content, err := afero.ReadFile(fs.Get(), path.Join(overlayDir, envFile))
+		if err != nil {
+			log.Warnf("Could not read existing env file %s: %v", envFile, err)
+			continue
+		}
+		env, err := input.ParseEnvFile(string(content))
+		if err != nil {
+			log.Warnf("Existing env file %s is not correctly defined: %v", envFile, err)
+			continue
+		}
+		for _, e := range env {
+			if _, ok := names[e.Name]; !ok {
+				names[e.Name] = envFile
+				application.Env = append(application.Env, e)
+			}
+		}
+	}
+	for _, literal := range literals {
+		e, err := input.ParseEnv(literal)
+		if err != nil {
+			log.Warnf("Existing env literal %s is not correctly defined: %v", literal, err)
+			continue
+		}
+		if _, ok := names[e.Name]; !ok {
+			names[e.Name] = literal
+			application.Env = append(application.Env, e)
+		}
+	}
+}
+
 func validateContainerResources(m map[string]string, name string) {
 	if len(m) > 0 && len(m) > 2 {
 		log.Fatalf("%s flag is not correctly defined. Too many elements set, expected only memory/cpu.", name)
@@ -171,6 +742,55 @@ func setContainerResource(m map[string]string, cpuOrMemory string, valuePtr *str
 	}
 }
 
+// targetExisting targets the container of the existing patch of the overlay when no container is set, so
+// that creating the patch again does not move it from a sidecar to the application container
+func targetExisting(application input.Application, overlayDir string, patch string) input.Application {
+	if Container() != "" {
+		return application
+	}
+	if name := kustomization.PatchedContainer(existingPatch(application, overlayDir, patch)); name != "" {
+		application.ContainerName = name
+	}
+	return application
+}
+
+// mountPath returns the path set by the flag, or else the path the existing patch of the overlay mounts
+// the volume at, or else the default path
+func mountPath(application input.Application, overlayDir string, patch string, volume string, flag string, def string) string {
+	existing := kustomization.MountPath(existingPatch(application, overlayDir, patch), application.Name+"-"+volume)
+	return useDefault(useDefault(def, existing), flag)
+}
+
+// existingPatch returns the content of the patch of an existing overlay, or "" when the overlay has no such patch
+func existingPatch(application input.Application, overlayDir string, patch string) string {
+	content, err := afero.ReadFile(fs.Get(), path.Join(overlayDir, patchName(application, patch)+".yaml"))
+	if err != nil {
+		return ""
+	}
+	return string(content)
+}
+
+// existingReplicas returns the replica count of an existing overlay, set by its replica patch or its
+// replicas entry, or 1 when the overlay does not change it
+func existingReplicas(application input.Application, k *input.Kustomization, overlayDir string) int {
+	if contains(k.Patches, patchName(application, "replica")+".yaml") {
+		if replicas := kustomization.PatchedReplicas(existingPatch(application, overlayDir, "replica")); replicas > 0 {
+			return replicas
+		}
+	}
+	for _, r := range k.Replicas {
+		if r.Name == application.Name {
+			return r.Count
+		}
+	}
+	return 1
+}
+
+// patchName returns the name of a patch for the application workload e.g statefulset-replica-patch
+func patchName(application input.Application, patch string) string {
+	return strings.ToLower(application.Kind) + "-" + patch + "-patch"
+}
+
 //
 // Context specific, should be moved
 //
@@ -181,3 +801,12 @@ func relativeBasePath() string {
 	}
 	return filepath.Join("../../", "base")
 }
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
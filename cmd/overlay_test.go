@@ -3,6 +3,7 @@ package cmd
 import (
 	"bytes"
 	"github.com/azunymous/easymodo/fs"
+	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/assert"
@@ -547,3 +548,804 @@ func TestCreateOverlayDeploymentMemoryResourceLimitsPatch(t *testing.T) {
 	assert.YAMLEq(t, string(expect), string(actual))
 	cleanup()
 }
+
+func TestCreateOverlayStatefulSetReplicaMergePatch(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"-r", "3",
+		"-d", "platform-stateful",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"statefulset-replica-patch.yaml", "kustomization.yaml"} {
+		p := path.Join("platform-stateful", "app-dev", file)
+		stat, fErr := fs.Get().Stat(p)
+		assert.Nil(t, fErr)
+		assert.False(t, stat.IsDir())
+
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-stateful", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), p)
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreateOverlayCronJobConfigMergePatch(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"-c", "configuration.yaml=configuration: test",
+		"--ingress=example.com",
+		"-d", "platform-cronjob",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"cronjob-config-patch.yaml", "kustomization.yaml"} {
+		p := path.Join("platform-cronjob", "app-dev", file)
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-cronjob", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), p)
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+
+	exists, _ := afero.Exists(fs.Get(), path.Join("platform-cronjob", "app-dev", "ingress.yaml"))
+	assert.False(t, exists)
+	cleanup()
+}
+
+func TestCreatesOverlayIngressResourceFileWithNamedPort(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--ingress=example.com",
+		"--ingressPort=metrics",
+		"-d=platform-with-named-ports",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join("platform-with-named-ports", "app-dev", "ingress.yaml")
+	stat, fErr := fs.Get().Stat(p)
+	assert.Nil(t, fErr)
+	assert.False(t, stat.IsDir())
+
+	expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-ingress-with-named-port", "app-dev", "ingress.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesOverlayIngressResourceFileWithTLSClassAndAnnotations(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--ingress=example.com/api",
+		"--ingress=example.com/admin",
+		"--ingress=www.example.com",
+		"--ingressClass=nginx",
+		"--ingressTls=example-tls",
+		"--ingressPathType=ImplementationSpecific",
+		"--ingressAnnotation=cert-manager.io/cluster-issuer=letsencrypt",
+		"--ingressAnnotation=nginx.ingress.kubernetes.io/proxy-body-size=8m",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, "app-dev", "ingress.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-ingress-with-tls", "app-dev", "ingress.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesOverlayIngressResourceFileWithWildcardHost(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--ingress=*.example.com",
+		"--ingressTls=wildcard-tls",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, "app-dev", "ingress.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-wildcard-ingress", "app-dev", "ingress.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreateOverlayDeploymentProbePatch(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--liveness", "periodSeconds=30,failureThreshold=5",
+		"--readiness", "http=/ready",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"deployment-probe-patch.yaml", "kustomization.yaml"} {
+		p := path.Join(platformDirDefault, "app-dev", file)
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-probes", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), p)
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesOverlayHorizontalPodAutoscaler(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--autoscale", "min=2,max=10,cpu=70,memory=80",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"hpa.yaml", "kustomization.yaml"} {
+		p := path.Join(platformDirDefault, "app-dev", file)
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-autoscaling", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), p)
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+
+	exists, _ := afero.Exists(fs.Get(), path.Join(platformDirDefault, "app-dev", "deployment-replica-patch.yaml"))
+	assert.False(t, exists)
+	cleanup()
+}
+
+func TestCreatesOverlayPodDisruptionBudget(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"-r", "3",
+		"--pdb", "minAvailable=2",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"pdb.yaml", "kustomization.yaml"} {
+		p := path.Join(platformDirDefault, "app-dev", file)
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-pdb", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), p)
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesOverlayNetworkPolicy(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--allow-from-namespace", "ingress-nginx",
+		"--allow-from-app", "monitoring/prometheus",
+		"--allow-to-app", "db",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"networkpolicy.yaml", "kustomization.yaml"} {
+		p := path.Join(platformDirDefault, "app-dev", file)
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-network-policy", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), p)
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesOverlayRoleForServiceAccount(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--grant", "pods,deployments.apps,configmaps=get,list",
+		"-d", "platform-with-service-account",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"role.yaml", "rolebinding.yaml", "kustomization.yaml"} {
+		p := path.Join("platform-with-service-account", "app-dev", file)
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-role", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), p)
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesOverlayRoleWithWildcardGrant(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--grant", "*=get,list",
+		"--grant", "deployments.apps=*",
+		"-d", "platform-with-service-account",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-wildcard-role", "app-dev", "role.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join("platform-with-service-account", "app-dev", "role.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Equal(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesOverlayServicePatch(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--service-type", "LoadBalancer",
+		"--service-annotation", "service.beta.kubernetes.io/aws-load-balancer-internal=true",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"service-patch.yaml", "kustomization.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-service-patch", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreateOverlayDeploymentSecurityContextPatch(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--security-context", "runAsUser=1000,fsGroup=2000",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, "app-dev", "deployment-security-patch.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-security-context", "app-dev", "deployment-security-patch.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesOverlayKustomizationWithCommonLabelsAndAnnotations(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--label", "team=payments,cost-center=42",
+		"--annotation", "owner=payments@example.com",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	p := path.Join(platformDirDefault, "app-dev", "kustomization.yaml")
+	expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-labels", "app-dev", "kustomization.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), p)
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesOverlayEnvConfigMapAndPatch(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--env", "GREETING=hello, world",
+		"--env-file", filepath.Join("overlayed-with-env", "dev.env"),
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"kustomization.yaml", "deployment-env-patch.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-env", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	exists, _ := afero.Exists(fs.Get(), path.Join(platformDirDefault, "app-dev", "dev.env"))
+	assert.True(t, exists)
+	cleanup()
+}
+
+func TestCreatesOverlaySecretFileGeneratorAndPatch(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--secretFile", "tls.key=" + filepath.Join("overlayed-with-secret-file", "tls.key"),
+		"--secretPath", "/etc/tls",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"kustomization.yaml", "deployment-secret-file-patch.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-secret-file", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	secret, _ := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", "tls.key"))
+	assert.Equal(t, "not a real key\n", string(secret))
+	cleanup()
+}
+
+func TestCreatesOverlayExternalSecret(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--external-secret", "DB_PASSWORD=dev/db#password",
+		"--external-secret", "API_KEY=dev/api-key",
+		"--secret-store", "vault",
+		"--secret-store-kind", "ClusterSecretStore",
+		"--refresh-interval", "15m",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"kustomization.yaml", "externalsecret.yaml", "deployment-secret-patch.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-external-secret", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesOverlaySealedSecret(t *testing.T) {
+	// kubeseal is replaced by cat, so the secret given to kubeseal for sealing is written
+	bin, _ := ioutil.TempDir("", "easymodo-test")
+	defer os.RemoveAll(bin)
+	_ = ioutil.WriteFile(filepath.Join(bin, "kubeseal"), []byte("#!/bin/sh\ncat\n"), 0700)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	_ = os.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"-e", "dev.env=PASSWORD=hunter2",
+		"--sealed-secret-cert", "cert.pem",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"kustomization.yaml", "sealedsecret.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-sealed-secret", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	exists, _ := afero.Exists(fs.Get(), path.Join(platformDirDefault, "app-dev", "dev.env"))
+	assert.False(t, exists)
+	cleanup()
+}
+
+func TestCreatesOverlayVolumesAndClaimOverrides(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--volume", "data,size=20Gi,class=fast",
+		"--volume", "logs=/logs",
+		"--emptyDir", "scratch=/scratch",
+		"-d", "platform-with-volumes",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"kustomization.yaml", "pvc.yaml", "pvc-patch.yaml", "deployment-volume-patch.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-volumes", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join("platform-with-volumes", "app-dev", file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesOverlayContainersPatchAndTargetsSidecar(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--init-container", `wait=busybox:1.36,command=["sh", "-c", "until nslookup db; do sleep 2; done"]`,
+		"--sidecar", "logs=fluent/fluent-bit:3.0",
+		"--container", "logs",
+		"--limits", "cpu=100m,memory=64Mi",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"kustomization.yaml", "deployment-containers-patch.yaml", "deployment-limits-patch.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-containers", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestCreatesOverlayProbePatchForSidecarWithPort(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--sidecar", "logs=fluent/fluent-bit:3.0",
+		"--container", "logs",
+		"--readiness", "http=/api/v1/health,port=2020",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-sidecar-probe", "app-dev", "deployment-probe-patch.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", "deployment-probe-patch.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+	cleanup()
+}
+
+func TestCreatesOverlaySchedulingPatch(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"--node-selector", "pool=prod",
+		"--toleration", "dedicated=prod:NoSchedule",
+		"--anti-affinity", "required",
+		"--topology-spread", "topology.kubernetes.io/zone,maxSkew=2",
+		"--priority-class", "high",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	for _, file := range []string{"kustomization.yaml", "deployment-scheduling-patch.yaml"} {
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-with-scheduling", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.YAMLEq(t, string(expect), string(actual))
+	}
+	cleanup()
+}
+
+func TestMergesOverlayIntoExistingOverlay(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	logs := new(bytes.Buffer)
+	log.SetOutput(logs)
+	defer log.SetOutput(w)
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"-c", "feature.yaml=enabled: true",
+		"--env", "LOG_LEVEL=info",
+		"--env", "MODE=fast",
+		"--replicas", "2",
+		"-d", "platform-with-overlay",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	expect, _ := ioutil.ReadFile(filepath.Join("overlayed-merged", "app-dev", "kustomization.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join("platform-with-overlay", "app-dev", "kustomization.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Equal(t, string(expect), string(actual))
+
+	expect, _ = ioutil.ReadFile(filepath.Join("overlayed-merged", "app-dev", "deployment-env-patch.yaml"))
+	actual, fErr = afero.ReadFile(fs.Get(), path.Join("platform-with-overlay", "app-dev", "deployment-env-patch.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.YAMLEq(t, string(expect), string(actual))
+
+	assert.Contains(t, logs.String(), "Overlay added file feature.yaml to config map app-config")
+	assert.Contains(t, logs.String(), "Overlay set LOG_LEVEL=info in config map app-env")
+	assert.Contains(t, logs.String(), "Overlay added patch deployment-replica-patch.yaml")
+	assert.NotContains(t, logs.String(), "monitoring.yaml")
+	cleanup()
+}
+
+func TestReportsExistingOverlayIsUpToDate(t *testing.T) {
+	cmd, buf, err := setUpOverlayCommand()
+	logs := new(bytes.Buffer)
+	log.SetOutput(logs)
+	defer log.SetOutput(w)
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"-c", "configuration.yaml=greeting: hello\n",
+		"-d", "platform-with-overlay",
+	})
+
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	expect, _ := ioutil.ReadFile(filepath.Join("platform-with-overlay", "app-dev", "kustomization.yaml"))
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join("platform-with-overlay", "app-dev", "kustomization.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Equal(t, string(expect), string(actual))
+	assert.Contains(t, logs.String(), "Overlay is up to date")
+	cleanup()
+}
+
+// createOverlay runs create overlay for app-dev with the flags, as when re-running it for an existing overlay
+func createOverlay(cmd *cobra.Command, flags ...string) {
+	ResetOptionalFlags()
+	cmd.SetArgs(append([]string{"create", "overlay", "app-dev"}, flags...))
+	_ = cmd.Execute()
+}
+
+func assertRerunFiles(t *testing.T, dir string, files ...string) {
+	for _, file := range files {
+		expect, _ := ioutil.ReadFile(filepath.Join("overlayed-rerun", "app-dev", file))
+		actual, fErr := afero.ReadFile(fs.Get(), path.Join(dir, "app-dev", file))
+		if fErr != nil {
+			t.Fatal(fErr)
+		}
+		assert.Equal(t, string(expect), string(actual))
+	}
+}
+
+func TestMergesPatchFlagsIntoExistingOverlayPatch(t *testing.T) {
+	cmd, _, _ := setUpOverlayCommand()
+	createOverlay(cmd, "--limits", "cpu=1,memory=512Mi")
+	createOverlay(cmd, "--limits", "memory=1Gi")
+
+	assertRerunFiles(t, platformDirDefault, "deployment-limits-patch.yaml")
+	cleanup()
+}
+
+func TestSetsConfigPathOfExistingOverlayPatch(t *testing.T) {
+	cmd, _, _ := setUpOverlayCommand()
+	logs := new(bytes.Buffer)
+	log.SetOutput(logs)
+	defer log.SetOutput(w)
+	createOverlay(cmd, "-c", "feature.yaml=enabled: true", "-p", "/etc/app/", "-d", "platform-with-overlay")
+
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join("platform-with-overlay", "app-dev", "deployment-config-patch.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Contains(t, string(actual), "mountPath: /etc/app/\n")
+	assert.NotContains(t, string(actual), "/config/")
+	assert.Contains(t, logs.String(), "Wrote deployment-config-patch.yaml")
+	cleanup()
+}
+
+func TestKeepsConfigPathOfExistingOverlayPatch(t *testing.T) {
+	cmd, _, _ := setUpOverlayCommand()
+	createOverlay(cmd, "-c", "feature.yaml=enabled: true", "-p", "/etc/app/")
+	createOverlay(cmd, "-c", "other.yaml=enabled: false")
+
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", "deployment-config-patch.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Contains(t, string(actual), "mountPath: /etc/app/\n")
+	assert.NotContains(t, string(actual), "/config/")
+	cleanup()
+}
+
+func TestKeepsContainerOfExistingOverlayEnvPatch(t *testing.T) {
+	cmd, _, _ := setUpOverlayCommand()
+	createOverlay(cmd, "--sidecar", "logs=fluent/fluent-bit:3.0", "--container", "logs", "--env", "LOG_LEVEL=info")
+	createOverlay(cmd, "--env", "FLUSH=5")
+
+	assertRerunFiles(t, platformDirDefault, "deployment-env-patch.yaml")
+	cleanup()
+}
+
+func TestMergesRoleRulesIntoExistingOverlay(t *testing.T) {
+	cmd, _, _ := setUpOverlayCommand()
+	createOverlay(cmd, "--grant", "pods=get", "-d", "platform-with-service-account")
+	createOverlay(cmd, "--grant", "configmaps=list", "-d", "platform-with-service-account")
+
+	assertRerunFiles(t, "platform-with-service-account", "role.yaml")
+	cleanup()
+}
+
+func TestMergesClaimsIntoExistingOverlay(t *testing.T) {
+	cmd, _, _ := setUpOverlayCommand()
+	createOverlay(cmd, "--volume", "a=/a", "-d", "platform-with-volumes")
+	createOverlay(cmd, "--volume", "b=/b", "-d", "platform-with-volumes")
+
+	assertRerunFiles(t, "platform-with-volumes", "pvc.yaml", "deployment-volume-patch.yaml")
+	cleanup()
+}
+
+func TestMergesNetworkPolicyPeersIntoExistingOverlay(t *testing.T) {
+	cmd, _, _ := setUpOverlayCommand()
+	createOverlay(cmd, "--allow-from-namespace", "ingress-nginx")
+	createOverlay(cmd, "--allow-from-app", "monitoring/prometheus")
+
+	assertRerunFiles(t, platformDirDefault, "networkpolicy.yaml")
+	cleanup()
+}
+
+func TestMergesDisruptionBudgetIntoExistingOverlay(t *testing.T) {
+	cmd, _, _ := setUpOverlayCommand()
+	createOverlay(cmd, "-r", "3", "--pdb", "minAvailable=2")
+	p := path.Join(platformDirDefault, "app-dev", "pdb.yaml")
+	pdb, _ := afero.ReadFile(fs.Get(), p)
+	_ = afero.WriteFile(fs.Get(), p, append(pdb, "  unhealthyPodEvictionPolicy: AlwaysAllow\n"...), 0644)
+	createOverlay(cmd, "--pdb", "maxUnavailable=1")
+
+	assertRerunFiles(t, platformDirDefault, "pdb.yaml")
+	cleanup()
+}
+
+func TestChecksDisruptionBudgetAgainstReplicasOfExistingOverlay(t *testing.T) {
+	cmd, _, _ := setUpOverlayCommand()
+	createOverlay(cmd)
+	p := path.Join(platformDirDefault, "app-dev", "kustomization.yaml")
+	k, _ := afero.ReadFile(fs.Get(), p)
+	_ = afero.WriteFile(fs.Get(), p, append(k, "replicas:\n- name: app\n  count: 3\n"...), 0644)
+	createOverlay(cmd, "--pdb", "minAvailable=2")
+
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", "pdb.yaml"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Contains(t, string(actual), "minAvailable: 2\n")
+	cleanup()
+}
+
+func TestMergesAutoscalerIntoExistingOverlay(t *testing.T) {
+	cmd, _, _ := setUpOverlayCommand()
+	createOverlay(cmd, "--autoscale", "max=5,memory=80")
+	createOverlay(cmd, "--autoscale", "max=10,cpu=70")
+
+	assertRerunFiles(t, platformDirDefault, "hpa.yaml")
+	cleanup()
+}
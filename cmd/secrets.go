@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"github.com/azunymous/easymodo/fs"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// secretsCmd represents the secrets command for working with encrypted secret env files
+var secretsCmd = &cobra.Command{
+	Use:   "secrets",
+	Short: "Decrypt and edit SOPS encrypted secret env files",
+	Long: `Decrypt and edit secret env files encrypted with SOPS and age when creating an overlay with
+--age-recipient or --age-key-file.
+
+The age key is read from --age-key-file, or the SOPS_AGE_KEY_FILE environment variable.
+SOPS must be installed.`,
+}
+
+// secretsDecryptCmd represents the secrets decrypt command
+var secretsDecryptCmd = &cobra.Command{
+	Use:   "decrypt <secret env file>",
+	Short: "Print a decrypted secret env file",
+	Run:   newSecretsDecryptCommand,
+	Args:  cobra.ExactArgs(1),
+}
+
+// secretsEditCmd represents the secrets edit command
+var secretsEditCmd = &cobra.Command{
+	Use:   "edit <secret env file>",
+	Short: "Edit an encrypted secret env file in $EDITOR",
+	Run:   newSecretsEditCommand,
+	Args:  cobra.ExactArgs(1),
+}
+
+func init() {
+	rootCmd.AddCommand(secretsCmd)
+	secretsCmd.AddCommand(secretsDecryptCmd)
+	secretsCmd.AddCommand(secretsEditCmd)
+
+	secretsCmd.PersistentFlags().StringVar(AgeKeyFileFlag(), "age-key-file", "", "age key file for decrypting secrets. Defaults to SOPS_AGE_KEY_FILE")
+}
+
+func newSecretsDecryptCommand(c *cobra.Command, args []string) {
+	content, err := afero.ReadFile(fs.Get(), args[0])
+	if err != nil {
+		log.Fatalf("Could not read %s: %v", args[0], err)
+	}
+	decrypted, err := decryptSecretEnv(content)
+	if err != nil {
+		log.Fatalf("Could not decrypt %s: %v", args[0], err)
+	}
+	_, _ = c.OutOrStdout().Write(decrypted)
+}
+
+func newSecretsEditCommand(_ *cobra.Command, args []string) {
+	sops := sopsCommand("--input-type", "dotenv", "--output-type", "dotenv", args[0])
+	sops.Stdin, sops.Stdout, sops.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := sops.Run(); err != nil {
+		log.Fatalf("Could not edit %s: %v", args[0], err)
+	}
+}
+
+// encryptSecrets returns whether secret env files are encrypted when written
+func encryptSecrets() bool {
+	return len(AgeRecipients()) > 0 || AgeKeyFile() != ""
+}
+
+// encryptSecretEnv encrypts the content of a secret env file with SOPS for the age recipients, which
+// include the public key of the age key file
+func encryptSecretEnv(fileName, content string) string {
+	recipients := AgeRecipients()
+	if AgeKeyFile() != "" {
+		recipient, err := agePublicKey(AgeKeyFile())
+		if err != nil {
+			log.Fatalf("Could not read age key file: %v", err)
+		}
+		recipients = append(recipients, recipient)
+	}
+
+	encrypted, err := runSops([]byte(content), "--encrypt", "--age", strings.Join(recipients, ","))
+	if err != nil {
+		log.Fatalf("Could not encrypt %s: %v", fileName, err)
+	}
+	return string(encrypted)
+}
+
+// decryptSecretEnv returns the decrypted content of a SOPS encrypted secret env file
+func decryptSecretEnv(content []byte) ([]byte, error) {
+	return runSops(content, "--decrypt")
+}
+
+// isSopsEncrypted returns whether env file content has been encrypted by SOPS
+func isSopsEncrypted(content []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if strings.HasPrefix(scanner.Text(), "sops_mac=") {
+			return true
+		}
+	}
+	return false
+}
+
+// agePublicKey reads the public key from the comment age-keygen writes in a key file
+func agePublicKey(keyFile string) (string, error) {
+	content, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return "", err
+	}
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		if line := scanner.Text(); strings.HasPrefix(line, "# public key: ") {
+			return strings.TrimPrefix(line, "# public key: "), nil
+		}
+	}
+	return "", fmt.Errorf("%s has no public key comment", keyFile)
+}
+
+func sopsCommand(args ...string) *exec.Cmd {
+	if _, err := exec.LookPath("sops"); err != nil {
+		log.Fatalf("sops is not installed")
+	}
+	sops := exec.Command("sops", args...)
+	sops.Env = os.Environ()
+	if AgeKeyFile() != "" {
+		sops.Env = append(sops.Env, "SOPS_AGE_KEY_FILE="+AgeKeyFile())
+	}
+	return sops
+}
+
+// runSops runs sops on dotenv content given through stdin, so that plaintext is only kept in memory
+func runSops(content []byte, args ...string) ([]byte, error) {
+	sops := sopsCommand(append(args, "--input-type", "dotenv", "--output-type", "dotenv", "/dev/stdin")...)
+	sops.Stdin = bytes.NewReader(content)
+	stderr := &bytes.Buffer{}
+	sops.Stderr = stderr
+	out, err := sops.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
+
+// decryptedFs returns a copy on write file system over the file system, holding the SOPS encrypted secret
+// env files of the directory decrypted with the age key in memory, so that they are never written to disk
+func decryptedFs(dir string) (afero.Fs, error) {
+	decrypted := afero.NewCopyOnWriteFs(afero.NewReadOnlyFs(fs.Get()), afero.NewMemMapFs())
+	err := afero.Walk(fs.Get(), dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return err
+		}
+		content, err := afero.ReadFile(fs.Get(), path)
+		if err != nil || !isSopsEncrypted(content) {
+			return err
+		}
+		log.Infof("Decrypting %s", path)
+		plaintext, err := decryptSecretEnv(content)
+		if err != nil {
+			return fmt.Errorf("could not decrypt %s: %v", path, err)
+		}
+		// kustomize reads files by their absolute path
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return err
+		}
+		return afero.WriteFile(decrypted, abs, plaintext, info.Mode())
+	})
+	return decrypted, err
+}
+
+// sealSecret seals a secret manifest with kubeseal using the public certificate of the sealed secrets
+// controller, returning the SealedSecret manifest
+func sealSecret(secret, cert string) string {
+	if _, err := exec.LookPath("kubeseal"); err != nil {
+		log.Fatalf("kubeseal is not installed")
+	}
+	kubeseal := exec.Command("kubeseal", "--cert", cert, "--format", "yaml")
+	kubeseal.Stdin = strings.NewReader(secret)
+	stderr := &bytes.Buffer{}
+	kubeseal.Stderr = stderr
+	sealed, err := kubeseal.Output()
+	if err != nil {
+		log.Fatalf("Could not seal secret: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(sealed)
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"encoding/base64"
+	"github.com/azunymous/easymodo/fs"
+	"github.com/azunymous/easymodo/kustomization"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+func TestDetectsSopsEncryptedEnvFiles(t *testing.T) {
+	encrypted := "PASSWORD=ENC[AES256_GCM,data:abc,iv:def,tag:ghi,type:str]\nsops_mac=ENC[AES256_GCM,data:jkl]\nsops_version=3.8.1\n"
+	assert.True(t, isSopsEncrypted([]byte(encrypted)))
+	assert.False(t, isSopsEncrypted([]byte("PASSWORD=hunter2\n")))
+}
+
+func TestReadsAgePublicKeyFromKeyFile(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "easymodo-test")
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "keys.txt")
+	key := "# created: 2020-01-01T00:00:00Z\n# public key: age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p\nAGE-SECRET-KEY-1EXAMPLE\n"
+	_ = ioutil.WriteFile(keyFile, []byte(key), 0600)
+
+	recipient, err := agePublicKey(keyFile)
+	assert.Nil(t, err)
+	assert.Equal(t, "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p", recipient)
+}
+
+// stubSops puts a sops on the PATH that wraps values in ENC[] when encrypting and unwraps them when
+// decrypting, logging its arguments to the returned file
+func stubSops(t *testing.T) (string, func()) {
+	bin, err := ioutil.TempDir("", "easymodo-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sops := `#!/bin/sh
+echo "$@" >> "` + filepath.Join(bin, "sops.log") + `"
+case "$1" in
+--encrypt) awk -F= '{ print $1 "=ENC[" substr($0, length($1) + 2) "]" } END { print "sops_mac=ENC[mac]" }' ;;
+--decrypt) grep -v '^sops_' | sed 's/=ENC\[\(.*\)\]$/=\1/' ;;
+esac
+`
+	_ = ioutil.WriteFile(filepath.Join(bin, "sops"), []byte(sops), 0700)
+	path := os.Getenv("PATH")
+	_ = os.Setenv("PATH", bin+string(os.PathListSeparator)+path)
+	return filepath.Join(bin, "sops.log"), func() {
+		_ = os.Setenv("PATH", path)
+		_ = os.RemoveAll(bin)
+	}
+}
+
+func TestEncryptsSecretEnvFilesWithSops(t *testing.T) {
+	sopsLog, restore := stubSops(t)
+	defer restore()
+
+	cmd, buf, err := setUpOverlayCommand()
+	cmd.SetArgs([]string{
+		"create",
+		"overlay",
+		"app-dev",
+		"-e", "dev.env=PASSWORD=hunter2",
+		"--age-recipient", "age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p",
+	})
+	_ = cmd.Execute()
+	println(buf.String())
+	println(err.String())
+
+	actual, fErr := afero.ReadFile(fs.Get(), path.Join(platformDirDefault, "app-dev", "dev.env"))
+	if fErr != nil {
+		t.Fatal(fErr)
+	}
+	assert.Equal(t, "PASSWORD=ENC[hunter2]\nsops_mac=ENC[mac]\n", string(actual))
+	args, _ := ioutil.ReadFile(sopsLog)
+	assert.Equal(t, "--encrypt --age age1ql3z7hjy54pw3hyww5ayyfg7zqgvc7w3j2elw8zmrj2kg5sfn9aqmcac8p --input-type dotenv --output-type dotenv /dev/stdin\n", string(args))
+	cleanup()
+}
+
+func TestVerifyDecryptsSecretEnvFilesInMemory(t *testing.T) {
+	sopsLog, restore := stubSops(t)
+	defer restore()
+
+	cmd, _, _ := setUpVerifyCommand()
+	cmd.SetArgs([]string{
+		"verify",
+		"-d", "platform-with-encrypted-secret",
+	})
+
+	assert.NotPanics(t, func() { _ = cmd.Execute() })
+	args, _ := ioutil.ReadFile(sopsLog)
+	assert.Equal(t, "--decrypt --input-type dotenv --output-type dotenv /dev/stdin\n", string(args))
+	cleanup()
+}
+
+func TestBuildsSecretsFromSecretEnvFilesDecryptedInMemory(t *testing.T) {
+	_, restore := stubSops(t)
+	defer restore()
+
+	_, _, _ = setUpVerifyCommand()
+	decrypted, dErr := decryptedFs("platform-with-encrypted-secret")
+	if dErr != nil {
+		t.Fatal(dErr)
+	}
+	out, bErr := kustomization.Build(decrypted, path.Join("platform-with-encrypted-secret", "dev"))
+	if bErr != nil {
+		t.Fatal(bErr)
+	}
+
+	assert.Contains(t, string(out), "PASSWORD: "+base64.StdEncoding.EncodeToString([]byte("hunter2")))
+	assert.NotContains(t, string(out), "ENC[")
+	assert.NotContains(t, string(out), "sops_")
+	encrypted, _ := ioutil.ReadFile(path.Join("platform-with-encrypted-secret", "dev", "dev.env"))
+	assert.Equal(t, "PASSWORD=ENC[hunter2]\nsops_mac=ENC[mac]\n", string(encrypted))
+	cleanup()
+}
+
+func TestDecryptsSecretEnvFile(t *testing.T) {
+	_, restore := stubSops(t)
+	defer restore()
+
+	cmd, buf, _ := setUpVerifyCommand()
+	cmd.SetArgs([]string{
+		"secrets",
+		"decrypt",
+		path.Join("platform-with-encrypted-secret", "dev", "dev.env"),
+	})
+	_ = cmd.Execute()
+
+	assert.Equal(t, "PASSWORD=hunter2\n", buf.String())
+	cleanup()
+}
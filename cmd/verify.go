@@ -1,13 +1,14 @@
 package cmd
 
 import (
+	"fmt"
 	"github.com/azunymous/easymodo/fs"
 	"github.com/azunymous/easymodo/input"
+	"github.com/azunymous/easymodo/kustomization"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"os"
-	"os/exec"
 	"path/filepath"
 )
 
@@ -18,7 +19,9 @@ var verifyCmd = &cobra.Command{
 	Long: `Verify kustomization files correctly build.
 This command will build all kustomizations in the provided directory (default: platform).
 
-Kustomize must be installed. kubectl kustomize is not currently supported.
+Kustomizations are built with kustomize in memory. Secret env files encrypted with SOPS are decrypted
+in memory, with the age key from SOPS_AGE_KEY_FILE, so generated secrets are built with the plaintext
+values. easymodo never writes decrypted secrets to disk.
 `,
 	Run:  newVerifyCommand,
 	Args: cobra.NoArgs,
@@ -29,24 +32,24 @@ func init() {
 }
 
 func newVerifyCommand(_ *cobra.Command, _ []string) {
-	_, err := exec.LookPath("kustomize")
-	if err != nil {
-		log.Fatalf("kustomize is not installed")
-	}
-
-	appName, _, _ := input.GetBaseApp(fs.Get(), Directory())
+	app := input.GetBaseApp(fs.Get(), Directory())
 
-	log.Infof("Verifying %s directory for application %s", Directory(), appName)
+	log.Infof("Verifying %s directory for application %s", Directory(), app.Name)
 
-	err = afero.Walk(fs.Get(), Directory(), returnWalkFunc())
+	decrypted, err := decryptedFs(Directory())
+	if err != nil {
+		log.Fatalf("Could not verify secrets in %s: %v", Directory(), err)
+	}
+	err = afero.Walk(fs.Get(), Directory(), returnWalkFunc(decrypted))
 
 	if err != nil {
-		log.Fatalf("Could not traverse directory: %v", err)
+		log.Fatalf("Could not verify %s: %v", Directory(), err)
 	}
 	log.Infof("SUCCESS")
 }
 
-func returnWalkFunc() filepath.WalkFunc {
+// returnWalkFunc returns a function building each kustomization found from the given file system
+func returnWalkFunc(buildFs afero.Fs) filepath.WalkFunc {
 	return func(path string, info os.FileInfo, err error) error {
 		if !info.IsDir() || path == Directory() {
 			return nil
@@ -58,11 +61,9 @@ func returnWalkFunc() filepath.WalkFunc {
 		}
 
 		log.Infof("Building kustomization %s", path)
-		kustomize := exec.Command("kustomize", "build", path)
-		kustomize.Stderr = os.Stderr
-		err = kustomize.Run()
+		_, err = kustomization.Build(buildFs, path)
 		if err != nil {
-			log.Fatalf("Failed to build kustomization %s: %v", path, err)
+			return fmt.Errorf("failed to build kustomization %s: %v", path, err)
 		}
 		return nil
 	}
@@ -20,8 +20,10 @@ import (
 // Interface Files is a store of files to be written to the file system
 type Files interface {
 	Add(string, string)
+	Content(string) string
 	WriteAll(directory, subDir string)
 	GetFilenames() []string
+	Changed(directory, subDir string) []string
 }
 
 type FileMap struct {
@@ -38,6 +40,11 @@ func (f *FileMap) Add(fileName, content string) {
 	f.files[fileName] = content
 }
 
+// Content returns the content of a file of the file map
+func (f *FileMap) Content(fileName string) string {
+	return f.files[fileName]
+}
+
 func (f *FileMap) write(appFs afero.Fs, dir, subDir string) error {
 	for fileName, content := range f.files {
 		if content == "" {
@@ -70,6 +77,22 @@ func (f *FileMap) GetFilenames() []string {
 	return fileNames
 }
 
+// Changed returns the filenames of a file map that are not in the given directory yet or have
+// different content
+func (f *FileMap) Changed(directory, subDir string) []string {
+	var changed []string
+	for _, name := range f.GetFilenames() {
+		if f.files[name] == "" {
+			continue
+		}
+		content, err := afero.ReadFile(appFs, path.Join(directory, subDir, name))
+		if err != nil || string(content) != f.files[name] {
+			changed = append(changed, name)
+		}
+	}
+	return changed
+}
+
 // WriteAll creates the given directory and writes all provided files to it.
 func (f *FileMap) WriteAll(directory, subDir string) {
 	_ = appFs.MkdirAll(path.Join(directory, subDir), 0755)
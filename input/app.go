@@ -2,85 +2,296 @@
 package input
 
 import (
+	"fmt"
 	"github.com/ghodss/yaml"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 	"path"
+	"strings"
 )
 
+// Supported Kubernetes workload kinds for the application
+const (
+	KindDeployment  = "Deployment"
+	KindStatefulSet = "StatefulSet"
+	KindDaemonSet   = "DaemonSet"
+	KindJob         = "Job"
+	KindCronJob     = "CronJob"
+)
+
+// workloadKinds lists the kinds a base can be created with, in the order the base directory is searched
+var workloadKinds = []string{KindDeployment, KindStatefulSet, KindDaemonSet, KindJob, KindCronJob}
+
 // Application defines the struct for a single application and possible configuration that would be
 // required for a deployment.
 type Application struct {
-	Name           string
-	Namespace      string
-	Stateful       bool
-	Image          string
-	ContainerName  string
-	ContainerPort  int
-	Protocol       string
-	Host           string
-	ConfigPath     string
-	Replicas       int
-	CpuRequests    string
-	MemoryRequests string
-	CpuLimits      string
-	MemoryLimits   string
+	Name               string
+	Namespace          string
+	Kind               string
+	Stateful           bool
+	Schedule           string
+	ConcurrencyPolicy  string
+	Image              string
+	ContainerName      string
+	InitContainers     []Container
+	Sidecars           []Container
+	Ports              []Port
+	ServiceType        string
+	ServiceAnnotations map[string]string
+	Ingress            *Ingress
+	Env                []EnvVar
+	ConfigPath         string
+	SecretPath         string
+	ExternalSecret     *ExternalSecret
+	LivenessProbe      *Probe
+	ReadinessProbe     *Probe
+	StartupProbe       *Probe
+	Volumes            []Volume
+	DataPath           string
+	StorageSize        string
+	Replicas           int
+	Autoscaling        *Autoscaling
+	DisruptionBudget   *DisruptionBudget
+	NetworkPolicy      *NetworkPolicy
+	Scheduling         *Scheduling
+	ServiceAccount     string
+	Security           *SecurityContext
+	Rules              []PolicyRule
+	CpuRequests        string
+	MemoryRequests     string
+	CpuLimits          string
+	MemoryLimits       string
 }
 
-// GetBaseApp reads the base deployment file and returns the set application name, image and port
-func GetBaseApp(fs afero.Fs, dir string) (string, string, int) {
-	df, err := afero.ReadFile(fs, path.Join(dir, "base", "deployment.yaml"))
-	if err != nil {
-		log.Fatalf("Could not open base deployment.yaml file: %v. Make sure you have a base deployment or call easymodo create base", err)
+// APIVersion returns the Kubernetes API version of the application's workload kind
+func (a Application) APIVersion() string {
+	if IsBatch(a.Kind) {
+		return "batch/v1"
+	}
+	return "apps/v1"
+}
+
+// Batch returns whether the application runs to completion as a Job or CronJob
+func (a Application) Batch() bool {
+	return IsBatch(a.Kind)
+}
+
+// Headless returns whether the application's service has no cluster IP
+func (a Application) Headless() bool {
+	return a.Stateful || a.ServiceType == ServiceHeadless
+}
+
+// IsBatch returns whether the workload kind runs to completion rather than serving requests, and so
+// has no service or ingress
+func IsBatch(kind string) bool {
+	return kind == KindJob || kind == KindCronJob
+}
+
+// IsScalable returns whether the workload kind has a replica count
+func IsScalable(kind string) bool {
+	return kind == KindDeployment || kind == KindStatefulSet
+}
+
+// ParseKind returns the workload kind matching the given name case insensitively e.g cronjob
+func ParseKind(name string) (string, bool) {
+	for _, kind := range workloadKinds {
+		if strings.EqualFold(kind, name) {
+			return kind, true
+		}
 	}
+	return "", false
+}
+
+// WorkloadFile returns the file name used for a workload resource of the given kind e.g statefulset.yaml
+func WorkloadFile(kind string) string {
+	return strings.ToLower(kind) + ".yaml"
+}
 
+// GetBaseApp reads the base workload file and returns an application with the set name, kind, image,
+// ports, volumes, init and sidecar containers and security profile
+func GetBaseApp(fs afero.Fs, dir string) Application {
+	df, err := readBaseWorkload(fs, dir)
 	if err != nil {
-		log.Fatalf("Could not read base deployment.yaml file: %v", err)
+		log.Fatalf("Could not open base workload file: %v. Make sure you have a base deployment or call easymodo create base", err)
 	}
 
-	type Deployment struct {
+	type PodTemplate struct {
+		Spec struct {
+			ServiceAccountName string `yaml:"serviceAccountName"`
+			Containers         []struct {
+				Name    string   `yaml:"name"`
+				Image   string   `yaml:"image"`
+				Command []string `yaml:"command"`
+				Ports   []struct {
+					Name          string `yaml:"name"`
+					ContainerPort int    `yaml:"containerPort"`
+					Protocol      string `yaml:"protocol"`
+				} `yaml:"ports"`
+				VolumeMounts []struct {
+					Name      string `yaml:"name"`
+					MountPath string `yaml:"mountPath"`
+					ReadOnly  bool   `yaml:"readOnly"`
+				} `yaml:"volumeMounts"`
+			} `yaml:"containers"`
+			InitContainers []struct {
+				Name    string   `yaml:"name"`
+				Image   string   `yaml:"image"`
+				Command []string `yaml:"command"`
+			} `yaml:"initContainers"`
+			SecurityContext struct {
+				RunAsNonRoot   bool `yaml:"runAsNonRoot"`
+				SeccompProfile *struct {
+					Type string `yaml:"type"`
+				} `yaml:"seccompProfile"`
+			} `yaml:"securityContext"`
+			Volumes []struct {
+				Name                  string `yaml:"name"`
+				PersistentVolumeClaim *struct {
+					ClaimName string `yaml:"claimName"`
+				} `yaml:"persistentVolumeClaim"`
+			} `yaml:"volumes"`
+		} `yaml:"spec"`
+	}
+
+	type Workload struct {
 		Kind     string `yaml:"kind"`
 		Metadata struct {
 			Name string `yaml:"name"`
 		} `yaml:"metadata"`
 		Spec struct {
-			Template struct {
+			Template             PodTemplate `yaml:"template"`
+			VolumeClaimTemplates []struct {
+				Metadata struct {
+					Name string `yaml:"name"`
+				} `yaml:"metadata"`
+			} `yaml:"volumeClaimTemplates"`
+			JobTemplate struct {
 				Spec struct {
-					Containers []struct {
-						Name  string `yaml:"name"`
-						Image string `yaml:"image"`
-						Ports []struct {
-							ContainerPort int `yaml:"containerPort"`
-						} `yaml:"ports"`
-					} `yaml:"containers"`
+					Template PodTemplate `yaml:"template"`
 				} `yaml:"spec"`
-			} `yaml:"template"`
+			} `yaml:"jobTemplate"`
 		} `yaml:"spec"`
 	}
 
-	deployment := Deployment{}
-	err = yaml.Unmarshal(df, &deployment)
+	workload := Workload{}
+	err = yaml.Unmarshal(df, &workload)
 
 	if err != nil {
-		log.Fatalf("Error unmarshalling deployment.yaml: %v", err)
+		log.Fatalf("Error unmarshalling %s: %v", WorkloadFile(workload.Kind), err)
 	}
 
-	if deployment.Kind != "Deployment" {
-		log.Fatalf("Kubernetes resource file is not a Deployment")
+	if !isWorkloadKind(workload.Kind) {
+		log.Fatalf("Kubernetes resource file is not a supported workload: %s", workload.Kind)
 	}
 
-	log.Debugf("Read base deployment file %s. Using %s as application name", deployment.Metadata.Name, deployment.Metadata.Name)
-	containers := deployment.Spec.Template.Spec.Containers
-	var port int
-	var image string
+	log.Debugf("Read base %s file %s. Using %s as application name", workload.Kind, workload.Metadata.Name, workload.Metadata.Name)
+	app := Application{
+		Name:     workload.Metadata.Name,
+		Kind:     workload.Kind,
+		Stateful: workload.Kind == KindStatefulSet,
+	}
+	pod := workload.Spec.Template
+	if workload.Kind == KindCronJob {
+		pod = workload.Spec.JobTemplate.Spec.Template
+	}
+	app.ServiceAccount = pod.Spec.ServiceAccountName
+	containers := pod.Spec.Containers
 	if len(containers) > 0 {
-		port = containers[0].Ports[0].ContainerPort
-		image = containers[0].Image
-	} else {
-		log.Warnf("Cannot determine container port from base deployment")
+		app.Image = containers[0].Image
+		for _, c := range containers[1:] {
+			app.Sidecars = append(app.Sidecars, Container{Name: c.Name, Image: c.Image, Command: c.Command})
+		}
+	}
+	for _, c := range pod.Spec.InitContainers {
+		app.InitContainers = append(app.InitContainers, Container{Name: c.Name, Image: c.Image, Command: c.Command})
+	}
+	if security := pod.Spec.SecurityContext; security.SeccompProfile != nil {
+		app.Security = &SecurityContext{Profile: SecurityBaseline}
+		if security.RunAsNonRoot {
+			app.Security.Profile = SecurityRestricted
+		}
+	}
+	if len(containers) > 0 {
+		for _, p := range containers[0].Ports {
+			port := Port{Name: p.Name, ContainerPort: p.ContainerPort, Protocol: p.Protocol}
+			if port.Protocol == "" {
+				port.Protocol = "TCP"
+			}
+			app.Ports = append(app.Ports, port)
+		}
+	}
+	volumes := make([]Volume, 0, len(pod.Spec.Volumes))
+	for _, v := range pod.Spec.Volumes {
+		volumes = append(volumes, Volume{Name: v.Name, Claim: v.PersistentVolumeClaim != nil})
+	}
+	for _, t := range workload.Spec.VolumeClaimTemplates {
+		if t.Metadata.Name != app.Name+"-data" {
+			volumes = append(volumes, Volume{Name: t.Metadata.Name, Claim: true})
+		}
+	}
+	for _, volume := range volumes {
+		if len(containers) > 0 {
+			for _, m := range containers[0].VolumeMounts {
+				if m.Name == volume.Name {
+					volume.MountPath, volume.ReadOnly = m.MountPath, m.ReadOnly
+				}
+			}
+		}
+		app.Volumes = append(app.Volumes, volume)
+	}
+	if len(app.Ports) == 0 && !IsBatch(workload.Kind) {
+		log.Warnf("Cannot determine container ports from base %s", workload.Kind)
+	}
+	if !IsBatch(workload.Kind) {
+		app.ServiceType = readBaseServiceType(fs, dir)
+	}
+	return app
+}
+
+// readBaseServiceType returns the type of the base service, or Headless if it has no cluster IP
+func readBaseServiceType(fs afero.Fs, dir string) string {
+	sf, err := afero.ReadFile(fs, path.Join(dir, "base", "service.yaml"))
+	if err != nil {
+		log.Debugf("Could not read base service: %v", err)
+		return ""
+	}
+	service := struct {
+		Spec struct {
+			Type      string `yaml:"type"`
+			ClusterIP string `yaml:"clusterIP"`
+		} `yaml:"spec"`
+	}{}
+	if err := yaml.Unmarshal(sf, &service); err != nil {
+		log.Warnf("Error unmarshalling service.yaml: %v", err)
+		return ""
+	}
+	if service.Spec.ClusterIP == "None" {
+		return ServiceHeadless
+	}
+	return service.Spec.Type
+}
+
+// readBaseWorkload returns the first workload file found in the base directory
+func readBaseWorkload(fs afero.Fs, dir string) ([]byte, error) {
+	files := make([]string, 0, len(workloadKinds))
+	for _, kind := range workloadKinds {
+		df, err := afero.ReadFile(fs, path.Join(dir, "base", WorkloadFile(kind)))
+		if err == nil {
+			return df, nil
+		}
+		files = append(files, WorkloadFile(kind))
+	}
+	return nil, fmt.Errorf("none of %s found in %s", strings.Join(files, ", "), path.Join(dir, "base"))
+}
+
+func isWorkloadKind(kind string) bool {
+	for _, k := range workloadKinds {
+		if k == kind {
+			return true
+		}
 	}
-	return deployment.Metadata.Name, image, port
+	return false
 }
 
 func ValidateNamespaceOrSuffix(suffix string, appName string, args []string, c *cobra.Command) (string, string) {
@@ -0,0 +1,53 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Autoscaling defines the replica range and target resource utilization percentages for a
+// HorizontalPodAutoscaler.
+type Autoscaling struct {
+	MinReplicas       int
+	MaxReplicas       int
+	CpuUtilization    int
+	MemoryUtilization int
+}
+
+// ParseAutoscaling creates autoscaling from key value pairs of min, max, cpu and memory e.g
+// min=2,max=10,cpu=70. min defaults to 1 and cpu to 80 when no utilization target is set. Returns nil
+// when no options are given.
+func ParseAutoscaling(options map[string]string) (*Autoscaling, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+	a := &Autoscaling{MinReplicas: 1}
+	values := map[string]*int{
+		"min":    &a.MinReplicas,
+		"max":    &a.MaxReplicas,
+		"cpu":    &a.CpuUtilization,
+		"memory": &a.MemoryUtilization,
+	}
+	for key, value := range options {
+		v, ok := values[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown autoscaling option %s, expected min, max, cpu or memory", key)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("autoscaling option %s must be a positive number, got %s", key, value)
+		}
+		*v = n
+	}
+
+	if a.MaxReplicas == 0 {
+		return nil, fmt.Errorf("autoscaling requires max replicas to be set")
+	}
+	if a.MinReplicas > a.MaxReplicas {
+		return nil, fmt.Errorf("autoscaling min replicas %d is greater than max replicas %d", a.MinReplicas, a.MaxReplicas)
+	}
+	if a.CpuUtilization == 0 && a.MemoryUtilization == 0 {
+		a.CpuUtilization = 80
+	}
+	return a, nil
+}
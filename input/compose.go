@@ -0,0 +1,256 @@
+package input
+
+import (
+	"fmt"
+	"github.com/ghodss/yaml"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ComposeService defines the parts of a docker-compose service that are imported into a base. Fields
+// accepting both the short and long compose syntax are decoded as they are written.
+type ComposeService struct {
+	Image       string        `json:"image"`
+	Ports       []interface{} `json:"ports"`
+	Environment interface{}   `json:"environment"`
+	EnvFile     interface{}   `json:"env_file"`
+	Volumes     []interface{} `json:"volumes"`
+}
+
+// Compose defines the services of a docker-compose file.
+type Compose struct {
+	Services map[string]ComposeService `json:"services"`
+}
+
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// ReadCompose reads and parses a docker-compose file
+func ReadCompose(fs afero.Fs, file string) (*Compose, error) {
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, err
+	}
+	compose := &Compose{}
+	if err := yaml.Unmarshal(content, compose); err != nil {
+		return nil, fmt.Errorf("error unmarshalling %s: %v", file, err)
+	}
+	if len(compose.Services) == 0 {
+		return nil, fmt.Errorf("%s has no services", file)
+	}
+	return compose, nil
+}
+
+// ServiceNames returns the names of the compose services in order
+func (c *Compose) ServiceNames() []string {
+	names := make([]string, 0, len(c.Services))
+	for name := range c.Services {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// KubernetesName returns a compose name as a valid Kubernetes resource name e.g my_db becomes my-db
+func KubernetesName(name string) string {
+	return strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// ContainerPorts returns the container ports of the service. Ports are named by protocol and number
+// when the service has several.
+func (s ComposeService) ContainerPorts() ([]Port, error) {
+	ports := make([]Port, 0, len(s.Ports))
+	for _, p := range s.Ports {
+		port, err := composePort(p)
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, port)
+	}
+	if len(ports) > 1 {
+		namePorts(ports)
+	}
+	return ports, nil
+}
+
+// composePort parses a port as [[ip:]published:]target[/protocol] or the long syntax
+func composePort(p interface{}) (Port, error) {
+	switch p := p.(type) {
+	case float64:
+		return Port{ContainerPort: int(p), Protocol: "TCP"}, nil
+	case string:
+		def := p[strings.LastIndex(p, ":")+1:]
+		protocol := "TCP"
+		if i := strings.Index(def, "/"); i >= 0 {
+			def, protocol = def[:i], strings.ToUpper(def[i+1:])
+		}
+		number, err := strconv.Atoi(def)
+		if err != nil {
+			return Port{}, fmt.Errorf("unsupported port %s, port ranges are not supported", p)
+		}
+		return Port{ContainerPort: number, Protocol: protocol}, nil
+	case map[string]interface{}:
+		target, ok := p["target"].(float64)
+		if !ok {
+			return Port{}, fmt.Errorf("port %v has no target", p)
+		}
+		protocol, _ := p["protocol"].(string)
+		if protocol == "" {
+			protocol = "tcp"
+		}
+		return Port{ContainerPort: int(target), Protocol: strings.ToUpper(protocol)}, nil
+	}
+	return Port{}, fmt.Errorf("unsupported port %v", p)
+}
+
+// Env returns the environment variables of the service, given as a list of KEY=VALUE or a map.
+// Variables without a value are read from the shell by compose and are skipped.
+func (s ComposeService) Env() []EnvVar {
+	var env []EnvVar
+	switch e := s.Environment.(type) {
+	case []interface{}:
+		for _, v := range e {
+			def := fmt.Sprint(v)
+			i := strings.Index(def, "=")
+			if i < 0 {
+				log.Warnf("Skipping environment variable %s without a value", def)
+				continue
+			}
+			env = append(env, EnvVar{Name: def[:i], Value: def[i+1:]})
+		}
+	case map[string]interface{}:
+		for name, v := range e {
+			if v == nil {
+				log.Warnf("Skipping environment variable %s without a value", name)
+				continue
+			}
+			env = append(env, EnvVar{Name: name, Value: composeValue(v)})
+		}
+		sort.Slice(env, func(i, j int) bool { return env[i].Name < env[j].Name })
+	}
+	return env
+}
+
+// composeValue returns a scalar compose value as written, without the exponent of large numbers
+func composeValue(v interface{}) string {
+	switch v := v.(type) {
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// ComposeEnvFile defines an env file of a compose service. Compose skips an env file that is not
+// required when it does not exist.
+type ComposeEnvFile struct {
+	Path     string
+	Required bool
+}
+
+// EnvFiles returns the env files of the service, given as a path, a list of paths or a list of
+// the long syntax with path and required
+func (s ComposeService) EnvFiles() ([]ComposeEnvFile, error) {
+	switch f := s.EnvFile.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []ComposeEnvFile{{Path: f, Required: true}}, nil
+	case []interface{}:
+		files := make([]ComposeEnvFile, 0, len(f))
+		for _, file := range f {
+			envFile, err := composeEnvFile(file)
+			if err != nil {
+				return nil, err
+			}
+			files = append(files, envFile)
+		}
+		return files, nil
+	}
+	return nil, fmt.Errorf("unsupported env_file %v", s.EnvFile)
+}
+
+// composeEnvFile parses an env file given as a path or the long syntax
+func composeEnvFile(f interface{}) (ComposeEnvFile, error) {
+	switch f := f.(type) {
+	case string:
+		return ComposeEnvFile{Path: f, Required: true}, nil
+	case map[string]interface{}:
+		p, ok := f["path"].(string)
+		if !ok || p == "" {
+			return ComposeEnvFile{}, fmt.Errorf("env_file %v has no path", f)
+		}
+		required := true
+		if r, ok := f["required"]; ok {
+			if required, ok = r.(bool); !ok {
+				return ComposeEnvFile{}, fmt.Errorf("env_file %s has an invalid required value %v", p, r)
+			}
+		}
+		return ComposeEnvFile{Path: p, Required: required}, nil
+	}
+	return ComposeEnvFile{}, fmt.Errorf("unsupported env_file %v", f)
+}
+
+// ContainerVolumes returns the volumes of the service. Named volumes are backed by a claim of the given
+// size. Bind mounts and anonymous volumes are replaced by an emptyDir, as host paths are not available
+// in the cluster.
+func (s ComposeService) ContainerVolumes(size string) ([]Volume, error) {
+	volumes := make([]Volume, 0, len(s.Volumes))
+	names := map[string]bool{}
+	for _, v := range s.Volumes {
+		var source, target, volumeType string
+		readOnly := false
+		switch v := v.(type) {
+		case string:
+			parts := strings.Split(v, ":")
+			target = parts[0]
+			if len(parts) > 1 {
+				source, target = parts[0], parts[1]
+			}
+			readOnly = len(parts) > 2 && strings.Contains(parts[2], "ro")
+		case map[string]interface{}:
+			source, _ = v["source"].(string)
+			target, _ = v["target"].(string)
+			volumeType, _ = v["type"].(string)
+			readOnly, _ = v["read_only"].(bool)
+		default:
+			return nil, fmt.Errorf("unsupported volume %v", v)
+		}
+		if target == "" {
+			return nil, fmt.Errorf("volume %v has no target", v)
+		}
+
+		volume := Volume{MountPath: target, ReadOnly: readOnly}
+		if isNamedVolume(source, volumeType) {
+			volume.Name, volume.Claim, volume.Size = KubernetesName(source), true, size
+		} else {
+			if source != "" {
+				log.Warnf("Replacing bind mount %s with an emptyDir at %s", source, target)
+			}
+			volume.Name = KubernetesName(path.Base(target))
+		}
+		name := volume.Name
+		for i := 2; names[volume.Name]; i++ {
+			volume.Name = fmt.Sprintf("%s-%d", name, i)
+		}
+		names[volume.Name] = true
+		volumes = append(volumes, volume)
+	}
+	return volumes, nil
+}
+
+func isNamedVolume(source, volumeType string) bool {
+	if volumeType != "" {
+		return volumeType == "volume" && source != ""
+	}
+	return source != "" && !strings.HasPrefix(source, ".") && !strings.HasPrefix(source, "/") && !strings.HasPrefix(source, "~")
+}
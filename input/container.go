@@ -0,0 +1,57 @@
+package input
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Container defines an init or sidecar container running alongside the application container.
+type Container struct {
+	Name    string
+	Image   string
+	Command []string
+}
+
+// ParseContainer parses a container definition of the form name=image[,command=<command>] e.g
+// migrate=app:latest,command=./migrate up. The command is split on whitespace, unless given as a JSON
+// array as for the exec form of a Dockerfile e.g command=["sh", "-c", "until nslookup db; do sleep 2; done"]
+func ParseContainer(definition string) (Container, error) {
+	c := Container{}
+	image := definition
+	if i := strings.Index(definition, ",command="); i >= 0 {
+		var command string
+		image, command = definition[:i], strings.TrimSpace(definition[i+len(",command="):])
+		c.Command = strings.Fields(command)
+		if strings.HasPrefix(command, "[") {
+			if err := json.Unmarshal([]byte(command), &c.Command); err != nil {
+				return Container{}, fmt.Errorf("invalid command %s in %s: %v", command, definition, err)
+			}
+		}
+		if len(c.Command) == 0 {
+			return Container{}, fmt.Errorf("invalid container %s: the command is empty", definition)
+		}
+	}
+	i := strings.Index(image, "=")
+	if i < 0 {
+		return Container{}, fmt.Errorf("invalid container %s, expected name=image[,command=<command>]", definition)
+	}
+	c.Name, c.Image = image[:i], image[i+1:]
+	if !volumeName.MatchString(c.Name) {
+		return Container{}, fmt.Errorf("invalid container name %s in %s: expected lowercase alphanumeric characters or '-'", c.Name, definition)
+	}
+	if c.Image == "" || strings.ContainsAny(c.Image, " ,") {
+		return Container{}, fmt.Errorf("invalid image %s in %s", c.Image, definition)
+	}
+	return c, nil
+}
+
+// FindContainer returns the container with the given name
+func FindContainer(containers []Container, name string) (Container, bool) {
+	for _, c := range containers {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return Container{}, false
+}
@@ -0,0 +1,74 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// DisruptionBudget defines either the minimum available or maximum unavailable pods for a
+// PodDisruptionBudget, as a number of pods or a percentage e.g 25%.
+type DisruptionBudget struct {
+	MinAvailable   string
+	MaxUnavailable string
+}
+
+// ParseDisruptionBudget creates a disruption budget from a single minAvailable or maxUnavailable key
+// value pair. Returns nil when no options are given.
+func ParseDisruptionBudget(options map[string]string) (*DisruptionBudget, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+	if len(options) > 1 {
+		return nil, fmt.Errorf("only one of minAvailable or maxUnavailable can be set")
+	}
+	d := &DisruptionBudget{}
+	for key, value := range options {
+		switch key {
+		case "minAvailable":
+			d.MinAvailable = value
+		case "maxUnavailable":
+			d.MaxUnavailable = value
+		default:
+			return nil, fmt.Errorf("unknown disruption budget option %s, expected minAvailable or maxUnavailable", key)
+		}
+		if _, _, err := parseIntOrPercent(value); err != nil {
+			return nil, fmt.Errorf("%s %v", key, err)
+		}
+	}
+	return d, nil
+}
+
+// Check returns an error when the disruption budget would never allow a pod to be evicted for the
+// given number of replicas, which blocks node drains during cluster upgrades. Percentages are rounded
+// up to a number of pods as done by the disruption controller.
+func (d DisruptionBudget) Check(replicas int) error {
+	if d.MinAvailable != "" {
+		if podsFor(d.MinAvailable, replicas) >= replicas {
+			return fmt.Errorf("minAvailable %s does not allow any of the %d replicas to be evicted", d.MinAvailable, replicas)
+		}
+	}
+	if d.MaxUnavailable != "" {
+		if podsFor(d.MaxUnavailable, replicas) == 0 {
+			return fmt.Errorf("maxUnavailable %s does not allow any of the %d replicas to be evicted", d.MaxUnavailable, replicas)
+		}
+	}
+	return nil
+}
+
+func podsFor(value string, replicas int) int {
+	n, percent, _ := parseIntOrPercent(value)
+	if percent {
+		return (n*replicas + 99) / 100
+	}
+	return n
+}
+
+func parseIntOrPercent(value string) (int, bool, error) {
+	percent := strings.HasSuffix(value, "%")
+	n, err := strconv.Atoi(strings.TrimSuffix(value, "%"))
+	if err != nil || n < 0 || (percent && n > 100) {
+		return 0, false, fmt.Errorf("must be a number of pods or a percentage, got %s", value)
+	}
+	return n, percent, nil
+}
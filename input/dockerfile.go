@@ -0,0 +1,164 @@
+package input
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/afero"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// VersionLabel is the OCI image label read for the version of the image built by a Dockerfile
+const VersionLabel = "org.opencontainers.image.version"
+
+// Dockerfile defines the parts of the final stage of a Dockerfile used to bootstrap a base.
+type Dockerfile struct {
+	Ports       []Port
+	HealthCheck *Probe
+	Version     string
+}
+
+// ReadDockerfile reads a Dockerfile and returns the ports exposed, the health check and the image
+// version label of its final stage.
+func ReadDockerfile(fs afero.Fs, file string) (*Dockerfile, error) {
+	content, err := afero.ReadFile(fs, file)
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Dockerfile{}
+	for _, line := range instructions(content) {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		args := strings.TrimSpace(line[len(fields[0]):])
+		switch strings.ToUpper(fields[0]) {
+		case "FROM":
+			*d = Dockerfile{}
+		case "EXPOSE":
+			for _, def := range fields[1:] {
+				port, err := ParsePort(def, "TCP")
+				if err != nil {
+					log.Warnf("Skipping exposed port %s: %v", def, err)
+					continue
+				}
+				d.Ports = append(d.Ports, port)
+			}
+		case "HEALTHCHECK":
+			d.HealthCheck, err = parseHealthCheck(args)
+			if err != nil {
+				return nil, err
+			}
+		case "LABEL":
+			if version, ok := label(args, VersionLabel); ok {
+				d.Version = version
+			}
+		}
+	}
+	if len(d.Ports) > 1 {
+		namePorts(d.Ports)
+	}
+	return d, nil
+}
+
+// instructions returns the instructions of a Dockerfile, joining continued lines and skipping comments.
+// As with docker, blank lines and comments do not end a continued line.
+func instructions(content []byte) []string {
+	var lines []string
+	current := ""
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(line, "#") || line == "" {
+			continue
+		}
+		if strings.HasSuffix(line, "\\") {
+			current += strings.TrimSuffix(line, "\\") + " "
+			continue
+		}
+		lines = append(lines, strings.TrimSpace(current+line))
+		current = ""
+	}
+	if strings.TrimSpace(current) != "" {
+		lines = append(lines, strings.TrimSpace(current))
+	}
+	return lines
+}
+
+// parseHealthCheck creates an exec probe from HEALTHCHECK [options] CMD <command>, using the interval,
+// timeout, start period and retries options as the probe timings. Returns nil for HEALTHCHECK NONE.
+func parseHealthCheck(args string) (*Probe, error) {
+	p := &Probe{Handler: ExecProbe}
+	for strings.HasPrefix(args, "--") {
+		option := args
+		if i := strings.IndexAny(args, " \t"); i >= 0 {
+			option, args = args[:i], strings.TrimSpace(args[i:])
+		} else {
+			args = ""
+		}
+		i := strings.Index(option, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid health check option %s", option)
+		}
+		name, value := option[2:i], option[i+1:]
+		var err error
+		switch name {
+		case "interval":
+			p.PeriodSeconds, err = seconds(value)
+		case "timeout":
+			p.TimeoutSeconds, err = seconds(value)
+		case "start-period":
+			p.InitialDelaySeconds, err = seconds(value)
+		case "retries":
+			p.FailureThreshold, err = strconv.Atoi(value)
+		default:
+			log.Debugf("Ignoring health check option %s", option)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid health check option %s: %v", option, err)
+		}
+	}
+
+	if strings.EqualFold(args, "NONE") {
+		return nil, nil
+	}
+	fields := strings.Fields(args)
+	if len(fields) < 2 || !strings.EqualFold(fields[0], "CMD") {
+		return nil, fmt.Errorf("invalid health check %s, expected CMD <command>", args)
+	}
+	command := strings.TrimSpace(args[len(fields[0]):])
+	if strings.HasPrefix(command, "[") {
+		if err := json.Unmarshal([]byte(command), &p.Command); err != nil {
+			return nil, fmt.Errorf("invalid health check command %s: %v", command, err)
+		}
+	} else {
+		p.Command = []string{"/bin/sh", "-c", command}
+	}
+	return p, nil
+}
+
+// seconds returns a Dockerfile duration e.g 1m30s in whole seconds, rounding up
+func seconds(duration string) (int, error) {
+	d, err := time.ParseDuration(duration)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Ceil(d.Seconds())), nil
+}
+
+// label returns the value of the given key in the arguments of a LABEL instruction
+func label(args, key string) (string, bool) {
+	for _, pair := range strings.Fields(args) {
+		i := strings.Index(pair, "=")
+		if i >= 0 && strings.Trim(pair[:i], `"`) == key {
+			return strings.Trim(pair[i+1:], `"`), true
+		}
+	}
+	return "", false
+}
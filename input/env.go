@@ -0,0 +1,47 @@
+package input
+
+import (
+	"bufio"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var envName = regexp.MustCompile(`^[-._a-zA-Z][-._a-zA-Z0-9]*$`)
+
+// EnvVar defines an environment variable set on the application container.
+type EnvVar struct {
+	Name  string
+	Value string
+}
+
+// ParseEnv parses an environment variable of the form KEY=VALUE
+func ParseEnv(definition string) (EnvVar, error) {
+	i := strings.Index(definition, "=")
+	if i < 0 {
+		return EnvVar{}, fmt.Errorf("invalid environment variable %s, expected KEY=VALUE", definition)
+	}
+	env := EnvVar{Name: definition[:i], Value: definition[i+1:]}
+	if !envName.MatchString(env.Name) {
+		return EnvVar{}, fmt.Errorf("invalid environment variable name %s", env.Name)
+	}
+	return env, nil
+}
+
+// ParseEnvFile parses the KEY=VALUE lines of an env file, skipping blank lines and comments
+func ParseEnvFile(content string) ([]EnvVar, error) {
+	var env []EnvVar
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		e, err := ParseEnv(line)
+		if err != nil {
+			return nil, err
+		}
+		env = append(env, e)
+	}
+	return env, nil
+}
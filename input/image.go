@@ -0,0 +1,33 @@
+package input
+
+import "strings"
+
+// ParseImage splits an image reference into its name, tag and digest e.g gcr.io/app:v1.2.3 or
+// app@sha256:<digest>. A port of the registry is kept in the name.
+func ParseImage(image string) (name, tag, digest string) {
+	name = strings.TrimSpace(image)
+	if i := strings.Index(name, "@"); i >= 0 {
+		name, digest = name[:i], name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i > strings.LastIndex(name, "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+	return name, tag, digest
+}
+
+// NewImage returns the images transformer entry replacing the base image with the given image, setting
+// a new name when the repository changes and a digest in place of the tag when given
+func NewImage(baseImage, image string) Image {
+	baseName, _, _ := ParseImage(baseImage)
+	name, tag, digest := ParseImage(image)
+	i := Image{Name: baseName}
+	if name != baseName {
+		i.NewName = name
+	}
+	if digest != "" {
+		i.Digest = digest
+	} else if tag != "" {
+		i.NewTag = tag
+	}
+	return i
+}
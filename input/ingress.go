@@ -0,0 +1,53 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Ingress defines the hosts and paths routed to the application's service on the given port, along with
+// the ingress class, TLS secret and annotations.
+type Ingress struct {
+	Rules       []IngressRule
+	PathType    string
+	ClassName   string
+	TLSSecret   string
+	Annotations map[string]string
+	Port        Port
+}
+
+// IngressRule defines the paths routed for a host.
+type IngressRule struct {
+	Host  string
+	Paths []string
+}
+
+// NewIngress creates an ingress from a list of hosts with optional paths e.g example.com/api. Paths
+// for the same host are grouped in the order given, defaulting to /.
+func NewIngress(hosts []string, pathType string) (*Ingress, error) {
+	switch pathType {
+	case "Prefix", "Exact", "ImplementationSpecific":
+	default:
+		return nil, fmt.Errorf("unknown path type %s, expected Prefix, Exact or ImplementationSpecific", pathType)
+	}
+
+	ingress := &Ingress{PathType: pathType, Annotations: map[string]string{}}
+	rules := map[string]int{}
+	for _, host := range hosts {
+		p := "/"
+		if i := strings.Index(host, "/"); i >= 0 {
+			host, p = host[:i], host[i:]
+		}
+		if host == "" {
+			return nil, fmt.Errorf("ingress path %s has no host", p)
+		}
+		i, ok := rules[host]
+		if !ok {
+			i = len(ingress.Rules)
+			rules[host] = i
+			ingress.Rules = append(ingress.Rules, IngressRule{Host: host})
+		}
+		ingress.Rules[i].Paths = append(ingress.Rules[i].Paths, p)
+	}
+	return ingress, nil
+}
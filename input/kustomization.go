@@ -1,12 +1,105 @@
 package input
 
+import (
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"sort"
+	"strings"
+)
+
 // Kustomization defines the struct for what is required for a kustomization
 type Kustomization struct {
-	Res       []string
-	Patches   []string
-	Config    map[string][]string
-	Secrets   map[string][]string
-	Namespace string
+	Res               []string
+	Patches           []string
+	Config            map[string][]string
+	ConfigEnvs        map[string][]string
+	ConfigLiterals    map[string][]string
+	Secrets           map[string][]string
+	SecretFiles       map[string][]string
+	SecretLiterals    map[string][]string
+	Namespace         string
+	NamePrefix        string
+	NameSuffix        string
+	CommonLabels      map[string]string
+	CommonAnnotations map[string]string
+	Labels            []Label
+	Components        []string
+	HelmCharts        []HelmChart
+	GeneratorOptions  *GeneratorOptions
+	Images            []Image
+	Replicas          []Replica
+	TargetedPatches   []Patch
+	JSONPatches       []JSON6902Patch
+	// Document is the kustomization.yaml the kustomization was read from, if any. Fields outside of the
+	// kustomization and their order are kept from the document when it is created again.
+	Document yaml.MapSlice
+}
+
+// Label defines labels added to resources, and optionally to selectors and pod templates
+type Label struct {
+	Pairs            map[string]string `yaml:"pairs"`
+	IncludeSelectors bool              `yaml:"includeSelectors,omitempty"`
+	IncludeTemplates bool              `yaml:"includeTemplates,omitempty"`
+}
+
+// HelmChart defines a Helm chart inflated into resources of the kustomization
+type HelmChart struct {
+	Name         string                 `yaml:"name"`
+	Repo         string                 `yaml:"repo,omitempty"`
+	Version      string                 `yaml:"version,omitempty"`
+	ReleaseName  string                 `yaml:"releaseName,omitempty"`
+	Namespace    string                 `yaml:"namespace,omitempty"`
+	ValuesFile   string                 `yaml:"valuesFile,omitempty"`
+	ValuesInline map[string]interface{} `yaml:"valuesInline,omitempty"`
+	IncludeCRDs  bool                   `yaml:"includeCRDs,omitempty"`
+}
+
+// GeneratorOptions defines the options of every config map and secret generator of the kustomization
+type GeneratorOptions struct {
+	Labels                map[string]string `yaml:"labels,omitempty"`
+	Annotations           map[string]string `yaml:"annotations,omitempty"`
+	DisableNameSuffixHash bool              `yaml:"disableNameSuffixHash,omitempty"`
+	Immutable             bool              `yaml:"immutable,omitempty"`
+}
+
+// Image defines the new name, tag or digest of an image used by the kustomization's resources
+type Image struct {
+	Name    string `yaml:"name"`
+	NewName string `yaml:"newName,omitempty"`
+	NewTag  string `yaml:"newTag,omitempty"`
+	Digest  string `yaml:"digest,omitempty"`
+}
+
+// Replica defines the replica count of a workload of the kustomization's resources
+type Replica struct {
+	Name  string `yaml:"name"`
+	Count int    `yaml:"count"`
+}
+
+// Patch defines a strategic merge or JSON patch from a file or inline, applied to the resources
+// selected by the target or named in the patch
+type Patch struct {
+	Path    string          `yaml:"path,omitempty"`
+	Patch   string          `yaml:"patch,omitempty"`
+	Target  *PatchTarget    `yaml:"target,omitempty"`
+	Options map[string]bool `yaml:"options,omitempty"`
+}
+
+// PatchTarget selects the resources a patch is applied to
+type PatchTarget struct {
+	Group              string `yaml:"group,omitempty"`
+	Version            string `yaml:"version,omitempty"`
+	Kind               string `yaml:"kind,omitempty"`
+	Name               string `yaml:"name,omitempty"`
+	Namespace          string `yaml:"namespace,omitempty"`
+	LabelSelector      string `yaml:"labelSelector,omitempty"`
+	AnnotationSelector string `yaml:"annotationSelector,omitempty"`
+}
+
+// JSON6902Patch defines a JSON patch file applied to the target resource
+type JSON6902Patch struct {
+	Target PatchTarget `yaml:"target"`
+	Path   string      `yaml:"path"`
 }
 
 // NewKustomization creates a new kustomization.
@@ -14,22 +107,254 @@ func NewKustomization(res []string, namespace string) *Kustomization {
 	return &Kustomization{Res: res, Namespace: namespace}
 }
 
-// AddResource adds a new kubernetes resource or base to the kustomization
+// AddResource adds a new kubernetes resource or base to the kustomization unless it is already added
 func (k *Kustomization) AddResource(fileName string) {
-	k.Res = append(k.Res, fileName)
+	if !contains(k.Res, fileName) {
+		k.Res = append(k.Res, fileName)
+	}
 }
 
-// AddPatch adds a new mergePatch to the kustomization
+// AddPatch adds a new mergePatch to the kustomization unless it is already added
 func (k *Kustomization) AddPatch(patchFilename string) {
-	k.Patches = append(k.Patches, patchFilename)
+	if !contains(k.Patches, patchFilename) {
+		k.Patches = append(k.Patches, patchFilename)
+	}
 }
 
-// AddConfig adds a file to a config map generator in the kustomization
+// AddImage adds an images transformer entry to the kustomization, replacing any entry for the same image
+func (k *Kustomization) AddImage(image Image) {
+	for i, existing := range k.Images {
+		if existing.Name == image.Name {
+			k.Images[i] = image
+			return
+		}
+	}
+	k.Images = append(k.Images, image)
+}
+
+// AddConfig adds a file to a config map generator in the kustomization unless it is already added
 func (k *Kustomization) AddConfig(name, configFilename string) {
-	k.Config[name] = append(k.Config[name], configFilename)
+	if !contains(k.Config[name], configFilename) {
+		k.Config[name] = append(k.Config[name], configFilename)
+	}
+}
+
+// AddConfigEnv adds an env file to a config map generator in the kustomization unless it is already added
+func (k *Kustomization) AddConfigEnv(name, envFilename string) {
+	if k.ConfigEnvs == nil {
+		k.ConfigEnvs = map[string][]string{}
+	}
+	if !contains(k.ConfigEnvs[name], envFilename) {
+		k.ConfigEnvs[name] = append(k.ConfigEnvs[name], envFilename)
+	}
+}
+
+// AddConfigLiteral adds a KEY=VALUE literal to a config map generator in the kustomization, replacing any
+// literal for the same key
+func (k *Kustomization) AddConfigLiteral(name, literal string) {
+	if k.ConfigLiterals == nil {
+		k.ConfigLiterals = map[string][]string{}
+	}
+	addLiteral(k.ConfigLiterals, name, literal)
+}
+
+// addLiteral adds a KEY=VALUE literal to a generator, replacing any literal for the same key
+func addLiteral(generators map[string][]string, name, literal string) {
+	key := strings.SplitN(literal, "=", 2)[0]
+	for i, existing := range generators[name] {
+		if strings.SplitN(existing, "=", 2)[0] == key {
+			generators[name][i] = literal
+			return
+		}
+	}
+	generators[name] = append(generators[name], literal)
+}
+
+// ConfigMapNames returns the names of the config map generators in the kustomization in order
+func (k *Kustomization) ConfigMapNames() []string {
+	return generatorNames(k.Config, k.ConfigEnvs, k.ConfigLiterals)
 }
 
-// AddSecret adds an env file to a secret generator in the kustomization
+// generatorNames returns the generator names used across the given generator sources in order
+func generatorNames(sources ...map[string][]string) []string {
+	seen := map[string]bool{}
+	var names []string
+	for _, generators := range sources {
+		for name := range generators {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// AddSecret adds an env file to a secret generator in the kustomization unless it is already added
 func (k *Kustomization) AddSecret(name, secretFilename string) {
-	k.Secrets[name] = append(k.Secrets[name], secretFilename)
+	if !contains(k.Secrets[name], secretFilename) {
+		k.Secrets[name] = append(k.Secrets[name], secretFilename)
+	}
+}
+
+// AddSecretFile adds a file to a secret generator in the kustomization unless it is already added
+func (k *Kustomization) AddSecretFile(name, secretFilename string) {
+	if k.SecretFiles == nil {
+		k.SecretFiles = map[string][]string{}
+	}
+	if !contains(k.SecretFiles[name], secretFilename) {
+		k.SecretFiles[name] = append(k.SecretFiles[name], secretFilename)
+	}
+}
+
+// AddSecretLiteral adds a KEY=VALUE literal to a secret generator in the kustomization, replacing any
+// literal for the same key
+func (k *Kustomization) AddSecretLiteral(name, literal string) {
+	if k.SecretLiterals == nil {
+		k.SecretLiterals = map[string][]string{}
+	}
+	addLiteral(k.SecretLiterals, name, literal)
+}
+
+// SecretNames returns the names of the secret generators in the kustomization in order
+func (k *Kustomization) SecretNames() []string {
+	return generatorNames(k.Secrets, k.SecretFiles, k.SecretLiterals)
+}
+
+// SetCommon sets the labels and annotations added to every resource of the kustomization
+func (k *Kustomization) SetCommon(labels, annotations map[string]string) {
+	k.CommonLabels = labels
+	k.CommonAnnotations = annotations
+}
+
+// AddCommon adds labels and annotations to every resource of the kustomization, keeping the ones already set
+func (k *Kustomization) AddCommon(labels, annotations map[string]string) {
+	if len(labels) > 0 && k.CommonLabels == nil {
+		k.CommonLabels = map[string]string{}
+	}
+	for key, value := range labels {
+		k.CommonLabels[key] = value
+	}
+	if len(annotations) > 0 && k.CommonAnnotations == nil {
+		k.CommonAnnotations = map[string]string{}
+	}
+	for key, value := range annotations {
+		k.CommonAnnotations[key] = value
+	}
+}
+
+// Copy returns a copy of the kustomization whose lists and maps can be added to without changing the kustomization
+func (k *Kustomization) Copy() *Kustomization {
+	c := *k
+	c.Res = append([]string(nil), k.Res...)
+	c.Patches = append([]string(nil), k.Patches...)
+	c.Config = copyGenerators(k.Config)
+	c.ConfigEnvs = copyGenerators(k.ConfigEnvs)
+	c.ConfigLiterals = copyGenerators(k.ConfigLiterals)
+	c.Secrets = copyGenerators(k.Secrets)
+	c.SecretFiles = copyGenerators(k.SecretFiles)
+	c.SecretLiterals = copyGenerators(k.SecretLiterals)
+	c.CommonLabels = copyMap(k.CommonLabels)
+	c.CommonAnnotations = copyMap(k.CommonAnnotations)
+	c.Labels = append([]Label(nil), k.Labels...)
+	c.Components = append([]string(nil), k.Components...)
+	c.HelmCharts = append([]HelmChart(nil), k.HelmCharts...)
+	c.Images = append([]Image(nil), k.Images...)
+	c.Replicas = append([]Replica(nil), k.Replicas...)
+	c.TargetedPatches = append([]Patch(nil), k.TargetedPatches...)
+	c.JSONPatches = append([]JSON6902Patch(nil), k.JSONPatches...)
+	c.Document = append(yaml.MapSlice(nil), k.Document...)
+	return &c
+}
+
+func copyGenerators(generators map[string][]string) map[string][]string {
+	if generators == nil {
+		return nil
+	}
+	c := make(map[string][]string, len(generators))
+	for name, sources := range generators {
+		c[name] = append([]string(nil), sources...)
+	}
+	return c
+}
+
+func copyMap(values map[string]string) map[string]string {
+	if values == nil {
+		return nil
+	}
+	c := make(map[string]string, len(values))
+	for key, value := range values {
+		c[key] = value
+	}
+	return c
+}
+
+// Changes describes what was added to or changed in the kustomization since the previous kustomization,
+// e.g "added patch deployment-config-patch.yaml"
+func (k *Kustomization) Changes(previous *Kustomization) []string {
+	var changes []string
+	if previous.Namespace == "" && k.Namespace != "" {
+		changes = append(changes, fmt.Sprintf("set namespace %s", k.Namespace))
+	} else if previous.Namespace != k.Namespace {
+		changes = append(changes, fmt.Sprintf("changed namespace from %s to %s", previous.Namespace, k.Namespace))
+	}
+	changes = append(changes, listChanges("added resource %s", previous.Res, k.Res)...)
+	changes = append(changes, listChanges("added patch %s", previous.Patches, k.Patches)...)
+	for _, generator := range []struct {
+		format            string
+		previous, sources map[string][]string
+	}{
+		{"added file %s to config map %s", previous.Config, k.Config},
+		{"added env file %s to config map %s", previous.ConfigEnvs, k.ConfigEnvs},
+		{"set %s in config map %s", previous.ConfigLiterals, k.ConfigLiterals},
+		{"added env file %s to secret %s", previous.Secrets, k.Secrets},
+		{"added file %s to secret %s", previous.SecretFiles, k.SecretFiles},
+	} {
+		for _, name := range generatorNames(generator.sources) {
+			changes = append(changes, listChanges(generator.format, generator.previous[name], generator.sources[name], name)...)
+		}
+	}
+	changes = append(changes, mapChanges("common label", previous.CommonLabels, k.CommonLabels)...)
+	changes = append(changes, mapChanges("common annotation", previous.CommonAnnotations, k.CommonAnnotations)...)
+	return changes
+}
+
+// listChanges describes each value added to a list with the format, followed by any further arguments
+func listChanges(format string, previous, values []string, args ...interface{}) []string {
+	var changes []string
+	for _, value := range values {
+		if !contains(previous, value) {
+			changes = append(changes, fmt.Sprintf(format, append([]interface{}{value}, args...)...))
+		}
+	}
+	return changes
+}
+
+// mapChanges describes the keys set or changed in a map, ordered by key
+func mapChanges(description string, previous, values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var changes []string
+	for _, key := range keys {
+		if old, ok := previous[key]; !ok {
+			changes = append(changes, fmt.Sprintf("added %s %s=%s", description, key, values[key]))
+		} else if old != values[key] {
+			changes = append(changes, fmt.Sprintf("changed %s %s=%s", description, key, values[key]))
+		}
+	}
+	return changes
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,44 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NetworkPolicy defines the peers allowed to reach the application and, when egress is restricted,
+// the peers the application can reach. Everything else is denied.
+type NetworkPolicy struct {
+	IngressPeers   []Peer
+	RestrictEgress bool
+	EgressPeers    []Peer
+}
+
+// Peer defines a namespace, an application or an application in another namespace for a network
+// policy rule.
+type Peer struct {
+	Namespace string
+	App       string
+}
+
+// ParsePeers creates peers from a list of namespaces and a list of applications. Applications in
+// another namespace are given as <namespace>/<application>.
+func ParsePeers(namespaces, apps []string) ([]Peer, error) {
+	peers := make([]Peer, 0, len(namespaces)+len(apps))
+	for _, ns := range namespaces {
+		if ns == "" || strings.Contains(ns, "/") {
+			return nil, fmt.Errorf("invalid namespace %q", ns)
+		}
+		peers = append(peers, Peer{Namespace: ns})
+	}
+	for _, app := range apps {
+		peer := Peer{App: app}
+		if i := strings.Index(app, "/"); i >= 0 {
+			peer = Peer{Namespace: app[:i], App: app[i+1:]}
+		}
+		if peer.App == "" || strings.Contains(peer.App, "/") || (strings.Contains(app, "/") && peer.Namespace == "") {
+			return nil, fmt.Errorf("invalid application %q, expected <application> or <namespace>/<application>", app)
+		}
+		peers = append(peers, peer)
+	}
+	return peers, nil
+}
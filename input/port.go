@@ -0,0 +1,104 @@
+package input
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Port defines a named container port and the matching service port.
+type Port struct {
+	Name          string
+	ContainerPort int
+	Protocol      string
+}
+
+var portName = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,13}[a-z0-9])?$`)
+
+// ParsePort parses a port definition of the form [name=]port[/protocol] e.g http=8080/TCP. The given
+// protocol is used when the definition does not set one.
+func ParsePort(definition, protocol string) (Port, error) {
+	p := Port{Protocol: protocol}
+	number := definition
+
+	if i := strings.Index(number, "="); i >= 0 {
+		p.Name, number = number[:i], number[i+1:]
+		if !portName.MatchString(p.Name) || !strings.ContainsAny(p.Name, "abcdefghijklmnopqrstuvwxyz") {
+			return Port{}, fmt.Errorf("invalid port name %s in %s: expected at most 15 lowercase alphanumeric characters or '-'", p.Name, definition)
+		}
+	}
+
+	if i := strings.Index(number, "/"); i >= 0 {
+		number, p.Protocol = number[:i], number[i+1:]
+	}
+	p.Protocol = strings.ToUpper(p.Protocol)
+	switch p.Protocol {
+	case "TCP", "UDP", "SCTP":
+	default:
+		return Port{}, fmt.Errorf("invalid protocol %s in %s: expected TCP, UDP or SCTP", p.Protocol, definition)
+	}
+
+	containerPort, err := strconv.Atoi(number)
+	if err != nil || containerPort < 1 || containerPort > 65535 {
+		return Port{}, fmt.Errorf("invalid port number %s in %s", number, definition)
+	}
+	p.ContainerPort = containerPort
+	return p, nil
+}
+
+// ParsePorts parses a list of port definitions, checking that ports are named when there are several
+// as required by a service.
+func ParsePorts(definitions []string, protocol string) ([]Port, error) {
+	ports := make([]Port, 0, len(definitions))
+	names := map[string]bool{}
+	for _, definition := range definitions {
+		p, err := ParsePort(definition, protocol)
+		if err != nil {
+			return nil, err
+		}
+		if len(definitions) > 1 && p.Name == "" {
+			return nil, fmt.Errorf("port %s must be named when setting multiple ports e.g http=%s", definition, definition)
+		}
+		if names[p.Name] && p.Name != "" {
+			return nil, fmt.Errorf("port name %s is used more than once", p.Name)
+		}
+		names[p.Name] = true
+		ports = append(ports, p)
+	}
+	return ports, nil
+}
+
+// FindPort returns the port with the given name or number, or the first port when none is given.
+func FindPort(ports []Port, selected string) (Port, error) {
+	if len(ports) == 0 {
+		return Port{}, fmt.Errorf("application has no ports")
+	}
+	for _, p := range ports {
+		if selected == "" || selected == p.Name || selected == strconv.Itoa(p.ContainerPort) {
+			return p, nil
+		}
+	}
+	return Port{}, fmt.Errorf("application has no port %s", selected)
+}
+
+// SelectPort returns the port with the given name or number, or the first port when none is given, as
+// a port name or a number when the port is unnamed.
+func SelectPort(ports []Port, selected string) (string, error) {
+	p, err := FindPort(ports, selected)
+	if err != nil {
+		return "", err
+	}
+	if p.Name != "" {
+		return p.Name, nil
+	}
+	return strconv.Itoa(p.ContainerPort), nil
+}
+
+// namePorts names each port by its protocol and number e.g tcp-8080, as ports must be named when a
+// container has several.
+func namePorts(ports []Port) {
+	for i := range ports {
+		ports[i].Name = strings.ToLower(ports[i].Protocol) + "-" + strconv.Itoa(ports[i].ContainerPort)
+	}
+}
@@ -0,0 +1,107 @@
+package input
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Probe handlers
+const (
+	HTTPGetProbe   = "httpGet"
+	TCPSocketProbe = "tcpSocket"
+	ExecProbe      = "exec"
+)
+
+// Probe defines a container liveness, readiness or startup probe. A probe without a handler only
+// sets timings, which is used for patching the probe of an existing container.
+type Probe struct {
+	Handler             string
+	Path                string
+	Port                string
+	Command             []string
+	InitialDelaySeconds int
+	PeriodSeconds       int
+	TimeoutSeconds      int
+	FailureThreshold    int
+	SuccessThreshold    int
+}
+
+// ParseProbe creates a probe from key value pairs. The handler is set by one of http=<path>,
+// tcp=<port> or exec=<command>, with port=<port> setting the port for http. Timings are set with the
+// Kubernetes field names e.g initialDelaySeconds=10. Ports default to the first of the given ports.
+// Returns nil when no options are given.
+func ParseProbe(options map[string]string, ports []Port) (*Probe, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+	p := &Probe{}
+	timings := map[string]*int{
+		"initialDelaySeconds": &p.InitialDelaySeconds,
+		"periodSeconds":       &p.PeriodSeconds,
+		"timeoutSeconds":      &p.TimeoutSeconds,
+		"failureThreshold":    &p.FailureThreshold,
+		"successThreshold":    &p.SuccessThreshold,
+	}
+
+	keys := make([]string, 0, len(options))
+	for key := range options {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := options[key]
+		switch key {
+		case "http":
+			p.Handler, p.Path = HTTPGetProbe, value
+		case "tcp":
+			p.Handler, p.Port = TCPSocketProbe, value
+		case "exec":
+			p.Handler, p.Command = ExecProbe, strings.Fields(value)
+		case "port":
+		default:
+			timing, ok := timings[key]
+			if !ok {
+				return nil, fmt.Errorf("unknown probe option %s", key)
+			}
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("probe option %s must be a positive number of seconds or count, got %s", key, value)
+			}
+			*timing = n
+		}
+	}
+
+	if count(options, "http", "tcp", "exec") > 1 {
+		return nil, fmt.Errorf("only one of http, tcp or exec can be set for a probe")
+	}
+	if port, ok := options["port"]; ok {
+		if p.Handler != HTTPGetProbe {
+			return nil, fmt.Errorf("probe port is only set for http probes, use tcp=<port> for tcp probes")
+		}
+		p.Port = port
+	}
+	if p.Handler == ExecProbe && len(p.Command) == 0 {
+		return nil, fmt.Errorf("exec probe requires a command")
+	}
+	if (p.Handler == HTTPGetProbe || p.Handler == TCPSocketProbe) && p.Port == "" {
+		port, err := SelectPort(ports, "")
+		if err != nil {
+			return nil, fmt.Errorf("cannot set probe port: %v", err)
+		}
+		p.Port = port
+	}
+	return p, nil
+}
+
+func count(options map[string]string, keys ...string) int {
+	n := 0
+	for _, key := range keys {
+		if _, ok := options[key]; ok {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,60 @@
+package input
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+var verbs = map[string]bool{
+	"get": true, "list": true, "watch": true, "create": true, "update": true, "patch": true,
+	"delete": true, "deletecollection": true, "*": true,
+}
+
+// PolicyRule defines the verbs granted on resources of an API group for a Role.
+type PolicyRule struct {
+	APIGroup  string
+	Resources []string
+	Verbs     []string
+}
+
+// ParseGrant creates policy rules from a grant of the form <resources>=<verbs> e.g
+// pods,deployments.apps=get,list,watch. Resources are given as <resource>[.<group>], with one rule
+// created per API group.
+func ParseGrant(grant string) ([]PolicyRule, error) {
+	i := strings.Index(grant, "=")
+	if i < 0 {
+		return nil, fmt.Errorf("invalid grant %s, expected <resources>=<verbs>", grant)
+	}
+
+	ruleVerbs := strings.Split(grant[i+1:], ",")
+	for _, verb := range ruleVerbs {
+		if !verbs[verb] {
+			return nil, fmt.Errorf("unknown verb %q in grant %s", verb, grant)
+		}
+	}
+
+	groups := map[string][]string{}
+	for _, resource := range strings.Split(grant[:i], ",") {
+		if resource == "" {
+			return nil, fmt.Errorf("empty resource in grant %s", grant)
+		}
+		group := ""
+		if j := strings.Index(resource, "."); j >= 0 {
+			resource, group = resource[:j], resource[j+1:]
+		}
+		groups[group] = append(groups[group], resource)
+	}
+
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+
+	rules := make([]PolicyRule, 0, len(groups))
+	for _, group := range names {
+		rules = append(rules, PolicyRule{APIGroup: group, Resources: groups[group], Verbs: ruleVerbs})
+	}
+	return rules, nil
+}
@@ -0,0 +1,152 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Pod anti-affinity types, either required for scheduling or preferred
+const (
+	AntiAffinityRequired  = "required"
+	AntiAffinityPreferred = "preferred"
+)
+
+// Scheduling defines the nodes the application's pods are scheduled on and how they are spread.
+type Scheduling struct {
+	NodeSelector      map[string]string
+	Tolerations       []Toleration
+	AntiAffinity      *AntiAffinity
+	TopologySpread    []TopologySpread
+	PriorityClassName string
+}
+
+// Toleration defines a taint tolerated by the application's pods. The operator is Exists when no value
+// is set, tolerating every value of the taint.
+type Toleration struct {
+	Key      string
+	Operator string
+	Value    string
+	Effect   string
+}
+
+// AntiAffinity defines a pod anti-affinity keeping the application's pods apart, for each value of the
+// topology key e.g on different nodes.
+type AntiAffinity struct {
+	Type        string
+	TopologyKey string
+}
+
+// Required returns whether pods are only scheduled apart, rather than preferably
+func (a AntiAffinity) Required() bool {
+	return a.Type == AntiAffinityRequired
+}
+
+// TopologySpread defines a topology spread constraint across the values of the topology key e.g zones.
+type TopologySpread struct {
+	TopologyKey       string
+	MaxSkew           int
+	WhenUnsatisfiable string
+}
+
+// ParseScheduling creates scheduling from the node selector, tolerations, anti-affinity, topology spread
+// constraints and priority class. Returns nil when none are given.
+func ParseScheduling(nodeSelector map[string]string, tolerations []string, antiAffinity string, spread []string, priorityClass string) (*Scheduling, error) {
+	if len(nodeSelector) == 0 && len(tolerations) == 0 && antiAffinity == "" && len(spread) == 0 && priorityClass == "" {
+		return nil, nil
+	}
+	s := &Scheduling{NodeSelector: nodeSelector, PriorityClassName: priorityClass}
+	for _, definition := range tolerations {
+		t, err := ParseToleration(definition)
+		if err != nil {
+			return nil, err
+		}
+		s.Tolerations = append(s.Tolerations, t)
+	}
+	if antiAffinity != "" {
+		a, err := ParseAntiAffinity(antiAffinity)
+		if err != nil {
+			return nil, err
+		}
+		s.AntiAffinity = &a
+	}
+	for _, definition := range spread {
+		t, err := ParseTopologySpread(definition)
+		if err != nil {
+			return nil, err
+		}
+		s.TopologySpread = append(s.TopologySpread, t)
+	}
+	return s, nil
+}
+
+// ParseToleration parses a toleration of a taint in the form key[=value][:effect] as used by kubectl
+// taint e.g dedicated=prod:NoSchedule. Every effect is tolerated when none is given.
+func ParseToleration(definition string) (Toleration, error) {
+	t := Toleration{Key: definition, Operator: "Exists"}
+	if i := strings.LastIndex(t.Key, ":"); i >= 0 {
+		t.Key, t.Effect = t.Key[:i], t.Key[i+1:]
+		switch t.Effect {
+		case "NoSchedule", "PreferNoSchedule", "NoExecute":
+		default:
+			return Toleration{}, fmt.Errorf("invalid taint effect %s in %s, expected NoSchedule, PreferNoSchedule or NoExecute", t.Effect, definition)
+		}
+	}
+	if i := strings.Index(t.Key, "="); i >= 0 {
+		t.Key, t.Value, t.Operator = t.Key[:i], t.Key[i+1:], "Equal"
+	}
+	if t.Key == "" {
+		return Toleration{}, fmt.Errorf("invalid toleration %s, expected key[=value][:effect]", definition)
+	}
+	return t, nil
+}
+
+// ParseAntiAffinity parses a pod anti-affinity of the form required|preferred[=topologyKey] e.g
+// preferred=topology.kubernetes.io/zone. The topology key defaults to the node hostname.
+func ParseAntiAffinity(definition string) (AntiAffinity, error) {
+	a := AntiAffinity{Type: definition, TopologyKey: "kubernetes.io/hostname"}
+	if i := strings.Index(definition, "="); i >= 0 {
+		a.Type, a.TopologyKey = definition[:i], definition[i+1:]
+	}
+	if a.Type != AntiAffinityRequired && a.Type != AntiAffinityPreferred {
+		return AntiAffinity{}, fmt.Errorf("invalid anti-affinity %s, expected required or preferred", a.Type)
+	}
+	if a.TopologyKey == "" {
+		return AntiAffinity{}, fmt.Errorf("invalid anti-affinity %s, the topology key is empty", definition)
+	}
+	return a, nil
+}
+
+// ParseTopologySpread parses a topology spread constraint of the form
+// topologyKey[,maxSkew=<n>][,whenUnsatisfiable=DoNotSchedule|ScheduleAnyway] e.g
+// topology.kubernetes.io/zone,maxSkew=1. Pods are spread with a max skew of 1, and not scheduled when
+// this cannot be satisfied, unless set otherwise.
+func ParseTopologySpread(definition string) (TopologySpread, error) {
+	options := strings.Split(definition, ",")
+	t := TopologySpread{TopologyKey: options[0], MaxSkew: 1, WhenUnsatisfiable: "DoNotSchedule"}
+	if t.TopologyKey == "" || strings.Contains(t.TopologyKey, "=") {
+		return TopologySpread{}, fmt.Errorf("invalid topology spread %s, expected a topology key e.g topology.kubernetes.io/zone", definition)
+	}
+	for _, option := range options[1:] {
+		kv := strings.SplitN(option, "=", 2)
+		if len(kv) != 2 {
+			return TopologySpread{}, fmt.Errorf("invalid topology spread option %s in %s: expected key=value", option, definition)
+		}
+		switch kv[0] {
+		case "maxSkew":
+			n, err := strconv.Atoi(kv[1])
+			if err != nil || n < 1 {
+				return TopologySpread{}, fmt.Errorf("topology spread maxSkew must be a positive number, got %s", kv[1])
+			}
+			t.MaxSkew = n
+		case "whenUnsatisfiable":
+			if kv[1] != "DoNotSchedule" && kv[1] != "ScheduleAnyway" {
+				return TopologySpread{}, fmt.Errorf("invalid whenUnsatisfiable %s, expected DoNotSchedule or ScheduleAnyway", kv[1])
+			}
+			t.WhenUnsatisfiable = kv[1]
+		default:
+			return TopologySpread{}, fmt.Errorf("unknown topology spread option %s, expected maxSkew or whenUnsatisfiable", kv[0])
+		}
+	}
+	return t, nil
+}
@@ -0,0 +1,70 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Secret store kinds an ExternalSecret can read from
+const (
+	SecretStore        = "SecretStore"
+	ClusterSecretStore = "ClusterSecretStore"
+)
+
+// ExternalSecret defines the keys of the application secret fetched from a secret store by the
+// External Secrets Operator.
+type ExternalSecret struct {
+	Store           string
+	StoreKind       string
+	RefreshInterval string
+	Data            []ExternalSecretData
+}
+
+// ExternalSecretData maps a key of the application secret to a key, and optional property, in the
+// secret store.
+type ExternalSecretData struct {
+	Key       string
+	RemoteKey string
+	Property  string
+}
+
+// ParseExternalSecret creates an external secret reading from the given store from definitions of the
+// form KEY=remote-key[#property] e.g DB_PASSWORD=prod/db#password
+func ParseExternalSecret(store, storeKind, refreshInterval string, definitions []string) (*ExternalSecret, error) {
+	if store == "" {
+		return nil, fmt.Errorf("a secret store must be set")
+	}
+	e := &ExternalSecret{Store: store, RefreshInterval: refreshInterval}
+	switch {
+	case strings.EqualFold(storeKind, SecretStore):
+		e.StoreKind = SecretStore
+	case strings.EqualFold(storeKind, ClusterSecretStore):
+		e.StoreKind = ClusterSecretStore
+	default:
+		return nil, fmt.Errorf("unknown secret store kind %s, expected SecretStore or ClusterSecretStore", storeKind)
+	}
+
+	keys := map[string]bool{}
+	for _, definition := range definitions {
+		i := strings.Index(definition, "=")
+		if i < 0 || i == len(definition)-1 {
+			return nil, fmt.Errorf("invalid external secret %s, expected KEY=remote-key[#property]", definition)
+		}
+		d := ExternalSecretData{Key: definition[:i], RemoteKey: definition[i+1:]}
+		if !envName.MatchString(d.Key) {
+			return nil, fmt.Errorf("invalid external secret key %s", d.Key)
+		}
+		if keys[d.Key] {
+			return nil, fmt.Errorf("external secret key %s is set more than once", d.Key)
+		}
+		keys[d.Key] = true
+		if j := strings.LastIndex(d.RemoteKey, "#"); j >= 0 {
+			d.RemoteKey, d.Property = d.RemoteKey[:j], d.RemoteKey[j+1:]
+		}
+		if d.RemoteKey == "" {
+			return nil, fmt.Errorf("invalid external secret %s, the remote key is empty", definition)
+		}
+		e.Data = append(e.Data, d)
+	}
+	return e, nil
+}
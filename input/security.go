@@ -0,0 +1,65 @@
+package input
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Security profiles for the pod and container security context. Restricted follows the restricted
+// Pod Security Standard, baseline only hardens what does not affect the image's user or filesystem.
+const (
+	SecurityRestricted = "restricted"
+	SecurityBaseline   = "baseline"
+	SecurityNone       = "none"
+)
+
+// SecurityContext defines the security profile of the application's pod and container, and the user,
+// group and filesystem group the pod runs as.
+type SecurityContext struct {
+	Profile    string
+	RunAsUser  int
+	RunAsGroup int
+	FSGroup    int
+}
+
+// Restricted returns whether the container runs as non root with a read only root filesystem and no
+// capabilities
+func (s SecurityContext) Restricted() bool {
+	return s.Profile == SecurityRestricted
+}
+
+// ParseSecurityProfile returns the security profile matching the given name case insensitively
+func ParseSecurityProfile(name string) (string, error) {
+	switch profile := strings.ToLower(name); profile {
+	case SecurityRestricted, SecurityBaseline, SecurityNone:
+		return profile, nil
+	}
+	return "", fmt.Errorf("unknown security profile %s, expected restricted, baseline or none", name)
+}
+
+// ParseSecurityContext creates a security context from key value pairs of runAsUser, runAsGroup and
+// fsGroup e.g runAsUser=1000,runAsGroup=1000. Returns nil when no options are given.
+func ParseSecurityContext(options map[string]string) (*SecurityContext, error) {
+	if len(options) == 0 {
+		return nil, nil
+	}
+	s := &SecurityContext{}
+	values := map[string]*int{
+		"runAsUser":  &s.RunAsUser,
+		"runAsGroup": &s.RunAsGroup,
+		"fsGroup":    &s.FSGroup,
+	}
+	for key, value := range options {
+		v, ok := values[key]
+		if !ok {
+			return nil, fmt.Errorf("unknown security context option %s, expected runAsUser, runAsGroup or fsGroup", key)
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			return nil, fmt.Errorf("security context option %s must be a non root ID, got %s", key, value)
+		}
+		*v = n
+	}
+	return s, nil
+}
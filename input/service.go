@@ -0,0 +1,27 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Supported service types. Headless is a ClusterIP service without a cluster IP, as required by a
+// StatefulSet.
+const (
+	ServiceClusterIP    = "ClusterIP"
+	ServiceNodePort     = "NodePort"
+	ServiceLoadBalancer = "LoadBalancer"
+	ServiceHeadless     = "Headless"
+)
+
+var serviceTypes = []string{ServiceClusterIP, ServiceNodePort, ServiceLoadBalancer, ServiceHeadless}
+
+// ParseServiceType returns the service type matching the given name case insensitively e.g nodeport
+func ParseServiceType(name string) (string, error) {
+	for _, t := range serviceTypes {
+		if strings.EqualFold(t, name) {
+			return t, nil
+		}
+	}
+	return "", fmt.Errorf("unknown service type %s, expected ClusterIP, NodePort, LoadBalancer or Headless", name)
+}
@@ -0,0 +1,104 @@
+package input
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Volume defines a volume mounted in the application container. Volumes with a claim are backed by a
+// PersistentVolumeClaim named <application>-<volume>, otherwise by an emptyDir.
+type Volume struct {
+	Name         string
+	MountPath    string
+	ReadOnly     bool
+	Claim        bool
+	Size         string
+	StorageClass string
+}
+
+var volumeName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`)
+
+// ParseVolume parses a volume definition of the form name=/path[,size=10Gi][,class=standard]. Size and
+// storage class are only set for volumes backed by a claim. The mount path can be left out when
+// overriding the size or storage class of an existing claim.
+func ParseVolume(definition string, claim bool) (Volume, error) {
+	options := strings.Split(definition, ",")
+	v := Volume{Name: options[0], Claim: claim}
+	if i := strings.Index(v.Name, "="); i >= 0 {
+		v.Name, v.MountPath = v.Name[:i], v.Name[i+1:]
+		if !strings.HasPrefix(v.MountPath, "/") {
+			return Volume{}, fmt.Errorf("invalid mount path %s in %s: expected an absolute path", v.MountPath, definition)
+		}
+	}
+	if !volumeName.MatchString(v.Name) {
+		return Volume{}, fmt.Errorf("invalid volume name %s in %s: expected lowercase alphanumeric characters or '-'", v.Name, definition)
+	}
+
+	for _, option := range options[1:] {
+		kv := strings.SplitN(option, "=", 2)
+		if len(kv) != 2 || kv[1] == "" {
+			return Volume{}, fmt.Errorf("invalid volume option %s in %s: expected key=value", option, definition)
+		}
+		if !claim {
+			return Volume{}, fmt.Errorf("invalid volume option %s in %s: an emptyDir has no size or storage class", option, definition)
+		}
+		switch kv[0] {
+		case "size":
+			v.Size = kv[1]
+		case "class":
+			v.StorageClass = kv[1]
+		default:
+			return Volume{}, fmt.Errorf("unknown volume option %s in %s, expected size or class", kv[0], definition)
+		}
+	}
+	return v, nil
+}
+
+// FindVolume returns the volume with the given name
+func FindVolume(volumes []Volume, name string) (Volume, bool) {
+	for _, v := range volumes {
+		if v.Name == name {
+			return v, true
+		}
+	}
+	return Volume{}, false
+}
+
+// Claims returns the application's volumes backed by a PersistentVolumeClaim. The claims of a StatefulSet
+// are volume claim templates instead, giving each pod its own claim.
+func (a Application) Claims() []Volume {
+	if a.Stateful {
+		return nil
+	}
+	return a.claims()
+}
+
+// ClaimTemplates returns the volumes of a StatefulSet claimed for each pod through volume claim templates
+func (a Application) ClaimTemplates() []Volume {
+	if !a.Stateful {
+		return nil
+	}
+	return a.claims()
+}
+
+// PodVolumes returns the volumes declared by the application's pods, which excludes volume claim templates
+func (a Application) PodVolumes() []Volume {
+	var volumes []Volume
+	for _, v := range a.Volumes {
+		if !v.Claim || !a.Stateful {
+			volumes = append(volumes, v)
+		}
+	}
+	return volumes
+}
+
+func (a Application) claims() []Volume {
+	var claims []Volume
+	for _, v := range a.Volumes {
+		if v.Claim {
+			claims = append(claims, v)
+		}
+	}
+	return claims
+}
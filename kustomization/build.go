@@ -0,0 +1,103 @@
+package kustomization
+
+import (
+	"fmt"
+	"github.com/spf13/afero"
+	"path/filepath"
+	"sigs.k8s.io/kustomize/api/krusty"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+)
+
+// Build builds the kustomization in the given directory with kustomize, reading the files from the
+// file system, and returns the resources as YAML. With a copy on write file system, files can be
+// changed in memory for the build without writing them to disk.
+func Build(fs afero.Fs, dir string) ([]byte, error) {
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fileSystem{fs}, dir)
+	if err != nil {
+		return nil, err
+	}
+	return resources.AsYaml()
+}
+
+// fileSystem is the file system kustomize builds from, backed by an afero file system
+type fileSystem struct {
+	fs afero.Fs
+}
+
+func (f fileSystem) Create(path string) (filesys.File, error) {
+	return f.fs.Create(path)
+}
+
+func (f fileSystem) Mkdir(path string) error {
+	return f.fs.Mkdir(path, 0755)
+}
+
+func (f fileSystem) MkdirAll(path string) error {
+	return f.fs.MkdirAll(path, 0755)
+}
+
+func (f fileSystem) RemoveAll(path string) error {
+	return f.fs.RemoveAll(path)
+}
+
+func (f fileSystem) Open(path string) (filesys.File, error) {
+	return f.fs.Open(path)
+}
+
+func (f fileSystem) IsDir(path string) bool {
+	isDir, err := afero.IsDir(f.fs, path)
+	return err == nil && isDir
+}
+
+func (f fileSystem) ReadDir(path string) ([]string, error) {
+	infos, err := afero.ReadDir(f.fs, path)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(infos))
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	return names, nil
+}
+
+// CleanedAbs returns the absolute directory of the path, and the file name when the path is a file
+func (f fileSystem) CleanedAbs(path string) (filesys.ConfirmedDir, string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", "", err
+	}
+	if f.IsDir(abs) {
+		return filesys.ConfirmedDir(abs), "", nil
+	}
+	if dir := filepath.Dir(abs); f.IsDir(dir) {
+		return filesys.ConfirmedDir(dir), filepath.Base(abs), nil
+	}
+	return "", "", fmt.Errorf("%s is not in a directory", path)
+}
+
+func (f fileSystem) Exists(path string) bool {
+	exists, err := afero.Exists(f.fs, path)
+	return err == nil && exists
+}
+
+// Glob returns the files matching the pattern, leaving out hidden files unless the pattern is for them
+func (f fileSystem) Glob(pattern string) ([]string, error) {
+	matches, err := afero.Glob(f.fs, pattern)
+	if err != nil || filesys.IsHiddenFilePath(pattern) {
+		return matches, err
+	}
+	return filesys.RemoveHiddenFiles(matches), nil
+}
+
+func (f fileSystem) ReadFile(path string) ([]byte, error) {
+	return afero.ReadFile(f.fs, path)
+}
+
+func (f fileSystem) WriteFile(path string, data []byte) error {
+	return afero.WriteFile(f.fs, path, data, 0644)
+}
+
+func (f fileSystem) Walk(path string, walkFn filepath.WalkFunc) error {
+	return afero.Walk(f.fs, path, walkFn)
+}
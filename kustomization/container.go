@@ -0,0 +1,42 @@
+package kustomization
+
+import "text/template"
+
+// container is the template source for an init or sidecar container of the application, given the
+// security context of the application's security profile.
+var container = `- name: {{.Name}}
+  image: {{.Image}}
+{{- if .Command}}
+  command:
+{{- range .Command}}
+    - {{printf "%q" .}}
+{{- end}}
+{{- end}}
+{{- with $}}
+` + indent(2, containerSecurityContext) + `
+{{- end}}`
+
+// initContainers is the template source for the init containers of the application.
+var initContainers = `{{- if .InitContainers}}
+initContainers:
+{{- range .InitContainers}}
+` + indent(2, container) + `
+{{- end}}
+{{- end}}`
+
+func DeploymentContainersPatch() *template.Template {
+	deploymentContainersPatch :=
+		podSpecPatch(initContainers + `
+{{- if .Sidecars}}
+containers:
+{{- range .Sidecars}}
+` + indent(2, container) + `
+{{- end}}
+{{- end}}`)
+
+	tmpl, err := template.New("deployment-containers").Parse(deploymentContainersPatch)
+	if err != nil {
+		panic("deploymentContainersPatch spec template is misconfigured")
+	}
+	return tmpl
+}
@@ -10,23 +10,13 @@ func Deployment() *template.Template {
 kind: Deployment
 metadata:
   name: {{.Name}}
-  labels:
-    app: {{.Name}}
+` + indent(2, labels) + `
 spec:
   replicas: 1
   selector:
     matchLabels:
       app: {{.Name}}
-  template:
-    metadata:
-      labels:
-        app: {{.Name}}
-    spec:
-      containers:
-        - name: {{.ContainerName}}
-          image: {{.Image}}
-          ports:
-            - containerPort: {{.ContainerPort}}
+` + indent(2, podTemplate) + `
 `
 
 	tmpl, err := template.New("deployment").Parse(deployment)
@@ -38,22 +28,15 @@ spec:
 
 func DeploymentConfigPatch() *template.Template {
 	deploymentConfigPatch :=
-		`apiVersion: apps/v1
-kind: Deployment
-metadata:
-  name: {{.Name}}
-spec:
-  template:
-    spec:
-      containers:
-        - name: {{.ContainerName}}
-          volumeMounts:
-            - mountPath: {{.ConfigPath}}
-              name: {{.Name}}-config
-      volumes:
-        - name: {{.Name}}-config
-          configMap:
-            name: {{.Name}}-config`
+		podSpecPatch(`containers:
+  - name: {{.ContainerName}}
+    volumeMounts:
+      - mountPath: {{.ConfigPath}}
+        name: {{.Name}}-config
+volumes:
+  - name: {{.Name}}-config
+    configMap:
+      name: {{.Name}}-config`)
 
 	tmpl, err := template.New("deployment-config").Parse(deploymentConfigPatch)
 	if err != nil {
@@ -62,20 +45,33 @@ spec:
 	return tmpl
 }
 
+func DeploymentSecretFilePatch() *template.Template {
+	deploymentSecretFilePatch :=
+		podSpecPatch(`containers:
+  - name: {{.ContainerName}}
+    volumeMounts:
+      - mountPath: {{.SecretPath}}
+        name: {{.Name}}-secret-files
+        readOnly: true
+volumes:
+  - name: {{.Name}}-secret-files
+    secret:
+      secretName: {{.Name}}-secret-files`)
+
+	tmpl, err := template.New("deployment-secret-file").Parse(deploymentSecretFilePatch)
+	if err != nil {
+		panic("deploymentSecretFilePatch spec template is misconfigured")
+	}
+	return tmpl
+}
+
 func DeploymentSecretPatch() *template.Template {
 	deploymentSecretPatch :=
-		`apiVersion: apps/v1
-kind: Deployment
-metadata:
-  name: {{.Name}}
-spec:
-  template:
-    spec:
-      containers:
-        - name: {{.ContainerName}}
-          envFrom:
-            - secretRef:
-                name: {{.Name}}-secret`
+		podSpecPatch(`containers:
+  - name: {{.ContainerName}}
+    envFrom:
+      - secretRef:
+          name: {{.Name}}-secret`)
 
 	tmpl, err := template.New("deployment-secret").Parse(deploymentSecretPatch)
 	if err != nil {
@@ -84,18 +80,31 @@ spec:
 	return tmpl
 }
 
+func DeploymentEnvPatch() *template.Template {
+	deploymentEnvPatch :=
+		podSpecPatch(`containers:
+  - name: {{.ContainerName}}
+    env:
+{{- range .Env}}
+      - name: {{.Name}}
+        valueFrom:
+          configMapKeyRef:
+            name: {{$.Name}}-env
+            key: {{.Name}}
+{{- end}}`)
+
+	tmpl, err := template.New("deployment-env").Parse(deploymentEnvPatch)
+	if err != nil {
+		panic("deploymentEnvPatch spec template is misconfigured")
+	}
+	return tmpl
+}
+
 func DeploymentImagePatch() *template.Template {
 	deploymentVersionPatch :=
-		`apiVersion: apps/v1
-kind: Deployment
-metadata:
-  name: {{.Name}}
-spec:
-  template:
-    spec:
-      containers:
-        - name: {{.ContainerName}}
-          image: {{.Image}}`
+		podSpecPatch(`containers:
+  - name: {{.ContainerName}}
+    image: {{.Image}}`)
 
 	tmpl, err := template.New("deployment-secret").Parse(deploymentVersionPatch)
 	if err != nil {
@@ -107,7 +116,7 @@ spec:
 func DeploymentReplicaPatch() *template.Template {
 	deploymentReplicaPatch :=
 		`apiVersion: apps/v1
-kind: Deployment
+kind: {{.Kind}}
 metadata:
   name: {{.Name}}
 spec:
@@ -122,22 +131,15 @@ spec:
 
 func DeploymentLimitsPatch() *template.Template {
 	deploymentLimitsPatch :=
-		`apiVersion: apps/v1
-kind: Deployment
-metadata:
-  name: {{.Name}}
-spec:
-  template:
-    spec:
-      containers:
-        - name: {{.ContainerName}}
-          resources:
-            limits:
+		podSpecPatch(`containers:
+  - name: {{.ContainerName}}
+    resources:
+      limits:
 {{- if .CpuLimits}}
-              cpu: {{.CpuLimits}}{{- end}}
+        cpu: {{.CpuLimits}}{{- end}}
 {{- if .MemoryLimits}}
-              memory: {{.MemoryLimits}}{{- end}}
-`
+        memory: {{.MemoryLimits}}{{- end}}
+`)
 
 	tmpl, err := template.New("deployment-limits").Parse(deploymentLimitsPatch)
 	if err != nil {
@@ -148,22 +150,15 @@ spec:
 
 func DeploymentRequestsPatch() *template.Template {
 	deploymentRequestsPatch :=
-		`apiVersion: apps/v1
-kind: Deployment
-metadata:
-  name: {{.Name}}
-spec:
-  template:
-    spec:
-      containers:
-        - name: {{.ContainerName}}
-          resources:
-            requests:
+		podSpecPatch(`containers:
+  - name: {{.ContainerName}}
+    resources:
+      requests:
 {{- if .CpuRequests}}
-              cpu: {{.CpuRequests}}{{- end}}
+        cpu: {{.CpuRequests}}{{- end}}
 {{- if .MemoryRequests}}
-              memory: {{.MemoryRequests}}{{- end}}
-`
+        memory: {{.MemoryRequests}}{{- end}}
+`)
 
 	tmpl, err := template.New("deployment-limits").Parse(deploymentRequestsPatch)
 	if err != nil {
@@ -33,27 +33,59 @@ func Generate(resourceName string, template *template.Template) Generator {
 	}
 }
 
-func BaseGenerators(ingressEnabled bool) []Generator {
+// BaseGenerators returns the generators for the base resources of an application for its workload
+// kind, ports, volumes, service account and ingress.
+func BaseGenerators(app input.Application) []Generator {
 	generators := []Generator{
-		Generate("deployment", Deployment()),
-		Generate("service", Service()),
+		Generate(strings.ToLower(app.Kind), workload(app.Kind)),
 	}
 
-	if ingressEnabled {
+	if app.ServiceAccount != "" {
+		generators = append(generators, Generate("serviceaccount", ServiceAccount()))
+	}
+
+	if len(app.Claims()) > 0 {
+		generators = append(generators, Generate("pvc", PersistentVolumeClaims()))
+	}
+
+	if app.Batch() || len(app.Ports) == 0 {
+		return generators
+	}
+
+	generators = append(generators, Generate("service", Service()))
+
+	if app.Ingress != nil {
 		generators = append(generators, Generate("ingress", Ingress()))
 	}
 
 	return generators
 }
 
+func workload(kind string) *template.Template {
+	switch kind {
+	case input.KindStatefulSet:
+		return StatefulSet()
+	case input.KindDaemonSet:
+		return DaemonSet()
+	case input.KindJob:
+		return Job()
+	case input.KindCronJob:
+		return CronJob()
+	}
+	return Deployment()
+}
+
+// Create adds the kustomization.yaml file for the kustomization to the files map
 func Create(kustomization *input.Kustomization, files fs.Files) {
-	content := strings.Builder{}
-	err := Kustomization().Execute(&content, kustomization)
+	f := NewFile(kustomization)
+	content, err := f.Marshal()
+	if kustomization.Document != nil {
+		content, err = f.marshalOnto(kustomization.Document)
+	}
 
 	if err != nil {
 		log.Fatalf("Could not create kustomization.yaml: %v", err)
 	}
-	log.Debugf("# Generated kustomization\n%s", content.String())
-	files.Add("kustomization.yaml", content.String())
-
+	log.Debugf("# Generated kustomization\n%s", content)
+	files.Add("kustomization.yaml", string(content))
 }
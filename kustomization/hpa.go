@@ -0,0 +1,44 @@
+package kustomization
+
+import "text/template"
+
+func HorizontalPodAutoscaler() *template.Template {
+	hpa :=
+		`apiVersion: autoscaling/v2
+kind: HorizontalPodAutoscaler
+metadata:
+  name: {{.Name}}
+spec:
+  scaleTargetRef:
+    apiVersion: {{.APIVersion}}
+    kind: {{.Kind}}
+    name: {{.Name}}
+{{- with .Autoscaling}}
+  minReplicas: {{.MinReplicas}}
+  maxReplicas: {{.MaxReplicas}}
+  metrics:
+{{- if .CpuUtilization}}
+    - type: Resource
+      resource:
+        name: cpu
+        target:
+          type: Utilization
+          averageUtilization: {{.CpuUtilization}}
+{{- end}}
+{{- if .MemoryUtilization}}
+    - type: Resource
+      resource:
+        name: memory
+        target:
+          type: Utilization
+          averageUtilization: {{.MemoryUtilization}}
+{{- end}}
+{{- end}}
+`
+
+	tmpl, err := template.New("hpa").Parse(hpa)
+	if err != nil {
+		panic("hpa spec template is misconfigured")
+	}
+	return tmpl
+}
@@ -4,21 +4,53 @@ import "text/template"
 
 func Ingress() *template.Template {
 	ingress :=
-		`apiVersion: networking.k8s.io/v1beta1
+		`apiVersion: networking.k8s.io/v1
 kind: Ingress
 metadata:
   name: {{.Name}}
+` + indent(2, labels) + `
+{{- with .Ingress}}
+{{- if .Annotations}}
+  annotations:
+{{- range $key, $value := .Annotations}}
+    {{$key}}: {{printf "%q" $value}}
+{{- end}}
+{{- end}}
 spec:
+{{- if .ClassName}}
+  ingressClassName: {{.ClassName}}
+{{- end}}
+{{- if .TLSSecret}}
+  tls:
+    - hosts:
+{{- range .Rules}}
+        - {{printf "%q" .Host}}
+{{- end}}
+      secretName: {{.TLSSecret}}
+{{- end}}
   rules:
-  - host: {{.Host}}
-    http:
-      paths:
-      - backend:
-          serviceName: {{.Name}}
-          servicePort: {{.ContainerPort}}
+{{- range .Rules}}
+    - host: {{printf "%q" .Host}}
+      http:
+        paths:
+{{- range .Paths}}
+          - path: {{.}}
+            pathType: {{$.Ingress.PathType}}
+            backend:
+              service:
+                name: {{$.Name}}
+                port:
+{{- if $.Ingress.Port.Name}}
+                  name: {{$.Ingress.Port.Name}}
+{{- else}}
+                  number: {{$.Ingress.Port.ContainerPort}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
 `
 
-	tmpl, err := template.New("service").Parse(ingress)
+	tmpl, err := template.New("ingress").Parse(ingress)
 	if err != nil {
 		panic("ingress spec template is misconfigured")
 	}
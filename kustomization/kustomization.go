@@ -1,46 +1,86 @@
 package kustomization
 
 import (
-	"text/template"
+	"github.com/azunymous/easymodo/input"
+	"gopkg.in/yaml.v2"
 )
 
-func Kustomization() *template.Template {
-	kustomization :=
-		`apiVersion: kustomize.config.k8s.io/v1beta1
-kind: Kustomization
-{{if $namespace := .Namespace}}namespace: {{$namespace}}{{end}}
-resources:
-{{range $key, $value := .Res }}- {{$value}}
-{{end}}
-{{if .Config -}}
-configMapGenerator:
-{{- range $key, $value := .Config}}
-  - name: {{$key}}
-    files:{{range $index, $filename := $value }}
-      - {{$filename}}
-{{- end}}
-{{- end}}
-{{- end}}
-{{if .Secrets -}}
-secretGenerator:
-{{- range $key, $value := .Secrets}}
-  - name: {{$key}}
-    envs:{{range $index, $filename := $value }}
-      - {{$filename}}
-{{- end}}
-{{- end}}
-{{- end}}
+// File is the kustomization.yaml schema. Fields are written in the order they are declared, with map
+// keys sorted, so that the same kustomization always renders the same file.
+type File struct {
+	APIVersion            string                  `yaml:"apiVersion"`
+	Kind                  string                  `yaml:"kind"`
+	NamePrefix            string                  `yaml:"namePrefix,omitempty"`
+	NameSuffix            string                  `yaml:"nameSuffix,omitempty"`
+	Namespace             string                  `yaml:"namespace,omitempty"`
+	CommonLabels          map[string]string       `yaml:"commonLabels,omitempty"`
+	Labels                []input.Label           `yaml:"labels,omitempty"`
+	CommonAnnotations     map[string]string       `yaml:"commonAnnotations,omitempty"`
+	Resources             []string                `yaml:"resources"`
+	Components            []string                `yaml:"components,omitempty"`
+	HelmCharts            []input.HelmChart       `yaml:"helmCharts,omitempty"`
+	ConfigMapGenerator    []GeneratorArgs         `yaml:"configMapGenerator,omitempty"`
+	SecretGenerator       []GeneratorArgs         `yaml:"secretGenerator,omitempty"`
+	GeneratorOptions      *input.GeneratorOptions `yaml:"generatorOptions,omitempty"`
+	Images                []input.Image           `yaml:"images,omitempty"`
+	Replicas              []input.Replica         `yaml:"replicas,omitempty"`
+	Patches               []input.Patch           `yaml:"patches,omitempty"`
+	PatchesStrategicMerge []string                `yaml:"patchesStrategicMerge,omitempty"`
+	PatchesJSON6902       []input.JSON6902Patch   `yaml:"patchesJson6902,omitempty"`
+}
 
-{{- if .Patches}}
-patchesStrategicMerge:
-{{range $key, $value := .Patches }}  - {{$value}}
-{{end}}
-{{- end}}
-`
+// GeneratorArgs defines a config map or secret generated from files, env files and literals
+type GeneratorArgs struct {
+	Name     string   `yaml:"name"`
+	Files    []string `yaml:"files,omitempty"`
+	Envs     []string `yaml:"envs,omitempty"`
+	Literals []string `yaml:"literals,omitempty"`
+}
 
-	tmpl, err := template.New("kustomization").Parse(kustomization)
-	if err != nil {
-		panic("kustomization spec template is misconfigured")
+// NewFile creates the kustomization.yaml file for a kustomization, with generators in name order
+func NewFile(k *input.Kustomization) File {
+	f := File{
+		APIVersion:            "kustomize.config.k8s.io/v1beta1",
+		Kind:                  "Kustomization",
+		NamePrefix:            k.NamePrefix,
+		NameSuffix:            k.NameSuffix,
+		Namespace:             k.Namespace,
+		CommonLabels:          k.CommonLabels,
+		Labels:                k.Labels,
+		CommonAnnotations:     k.CommonAnnotations,
+		Resources:             k.Res,
+		Components:            k.Components,
+		HelmCharts:            k.HelmCharts,
+		GeneratorOptions:      k.GeneratorOptions,
+		Images:                k.Images,
+		Replicas:              k.Replicas,
+		Patches:               k.TargetedPatches,
+		PatchesStrategicMerge: k.Patches,
+		PatchesJSON6902:       k.JSONPatches,
+	}
+	if f.Resources == nil {
+		f.Resources = []string{}
+	}
+	for _, name := range k.ConfigMapNames() {
+		f.ConfigMapGenerator = append(f.ConfigMapGenerator, GeneratorArgs{
+			Name:     name,
+			Files:    k.Config[name],
+			Envs:     k.ConfigEnvs[name],
+			Literals: k.ConfigLiterals[name],
+		})
 	}
-	return tmpl
+	for _, name := range k.SecretNames() {
+		f.SecretGenerator = append(f.SecretGenerator, GeneratorArgs{
+			Name:     name,
+			Envs:     k.Secrets[name],
+			Files:    k.SecretFiles[name],
+			Literals: k.SecretLiterals[name],
+		})
+	}
+	return f
+}
+
+// Marshal returns the YAML of the kustomization file
+func (f File) Marshal() ([]byte, error) {
+	return yaml.Marshal(f)
 }
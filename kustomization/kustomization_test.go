@@ -0,0 +1,76 @@
+package kustomization
+
+import (
+	"github.com/azunymous/easymodo/input"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestRendersOverlayKustomization(t *testing.T) {
+	k := &input.Kustomization{
+		Res:            []string{"../base", "hpa.yaml"},
+		Patches:        []string{"deployment-config-patch.yaml", "deployment-secret-patch.yaml"},
+		Config:         map[string][]string{"app-config": {"config.yaml"}},
+		ConfigLiterals: map[string][]string{"app-env": {"LOG_LEVEL=debug", "GREETING=hello, world"}},
+		ConfigEnvs:     map[string][]string{"app-env": {"dev.env"}},
+		Secrets:        map[string][]string{"app-secret": {"secret.env"}},
+		SecretFiles:    map[string][]string{"app-secret-files": {"tls.key", "tls.crt"}},
+		Namespace:      "app-dev",
+		CommonLabels:   map[string]string{"team": "payments", "cost-center": "42"},
+	}
+
+	assertRenders(t, k, "overlay.yaml")
+}
+
+func TestRendersFullKustomizationSchema(t *testing.T) {
+	k := &input.Kustomization{
+		Res:        []string{"../base"},
+		Namespace:  "app-prod",
+		NamePrefix: "prod-",
+		NameSuffix: "-v2",
+		Labels: []input.Label{
+			{Pairs: map[string]string{"team": "payments"}, IncludeTemplates: true},
+		},
+		CommonAnnotations: map[string]string{"owner": "payments@example.com"},
+		Components:        []string{"../components/monitoring"},
+		HelmCharts: []input.HelmChart{{
+			Name:         "redis",
+			Repo:         "https://charts.bitnami.com/bitnami",
+			Version:      "19.0.1",
+			ReleaseName:  "cache",
+			ValuesInline: map[string]interface{}{"architecture": "standalone", "auth": map[string]interface{}{"enabled": false}},
+		}},
+		GeneratorOptions: &input.GeneratorOptions{DisableNameSuffixHash: true, Labels: map[string]string{"generated": "true"}},
+		Images:           []input.Image{{Name: "app", NewName: "gcr.io/prod/app", NewTag: "v1.2.3"}},
+		Replicas:         []input.Replica{{Name: "app", Count: 3}},
+		TargetedPatches: []input.Patch{
+			{Path: "deployment-limits-patch.yaml"},
+			{
+				Patch:  "- op: replace\n  path: /spec/template/spec/priorityClassName\n  value: high\n",
+				Target: &input.PatchTarget{Kind: "Deployment", LabelSelector: "app=app"},
+			},
+		},
+		JSONPatches: []input.JSON6902Patch{{
+			Target: input.PatchTarget{Group: "networking.k8s.io", Version: "v1", Kind: "Ingress", Name: "app"},
+			Path:   "ingress-json-patch.yaml",
+		}},
+	}
+
+	assertRenders(t, k, "full.yaml")
+}
+
+func TestRendersEmptyResources(t *testing.T) {
+	assertRenders(t, &input.Kustomization{}, "empty.yaml")
+}
+
+func assertRenders(t *testing.T, k *input.Kustomization, golden string) {
+	actual, err := NewFile(k).Marshal()
+	assert.Nil(t, err)
+	again, _ := NewFile(k).Marshal()
+	assert.Equal(t, string(actual), string(again))
+
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", golden))
+	assert.Equal(t, string(expect), string(actual))
+}
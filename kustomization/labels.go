@@ -0,0 +1,8 @@
+package kustomization
+
+// labels is the template source for the labels of base resources. The app label is kept alongside the
+// recommended labels as it is used by selectors, which cannot be changed once a workload is created.
+var labels = `labels:
+  app: {{.Name}}
+  app.kubernetes.io/name: {{.Name}}
+  app.kubernetes.io/managed-by: kustomize`
@@ -0,0 +1,261 @@
+package kustomization
+
+import (
+	"bytes"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io"
+	"reflect"
+	"strings"
+)
+
+// exclusiveFields are the fields of a mapping of which only one can be set, such as the handlers of a
+// probe. Setting one of them replaces the others.
+var exclusiveFields = [][]string{
+	{"httpGet", "tcpSocket", "exec", "grpc"},
+	{"minAvailable", "maxUnavailable"},
+}
+
+// MergeManifest merges a manifest created again into the existing manifest, so that what the existing
+// manifest has is kept. Documents are matched by kind and name. Values of the created manifest replace
+// existing values, items of lists are matched by name and other items are added unless already present.
+// A mapping marked with $patch: replace replaces the existing mapping. When nothing is added to the
+// created or existing manifest, it is returned as it is.
+func MergeManifest(existing, created string) (string, error) {
+	existingDocs, err := documents(existing)
+	if err != nil {
+		return "", err
+	}
+	createdDocs, err := documents(created)
+	if err != nil {
+		return "", err
+	}
+
+	var merged []interface{}
+	matched := map[int]bool{}
+	for _, e := range existingDocs {
+		for i, c := range createdDocs {
+			if !matched[i] && documentName(e) == documentName(c) {
+				e = mergeValue(e, c)
+				matched[i] = true
+				break
+			}
+		}
+		merged = append(merged, e)
+	}
+	for i, c := range createdDocs {
+		if !matched[i] {
+			merged = append(merged, c)
+		}
+	}
+
+	switch {
+	case reflect.DeepEqual(merged, createdDocs):
+		return created, nil
+	case reflect.DeepEqual(merged, existingDocs):
+		return existing, nil
+	}
+	var out []string
+	for _, doc := range merged {
+		content, err := yaml.Marshal(doc)
+		if err != nil {
+			return "", err
+		}
+		out = append(out, string(content))
+	}
+	return strings.Join(out, "---\n"), nil
+}
+
+// documents returns the YAML documents of a manifest
+func documents(manifest string) ([]interface{}, error) {
+	var docs []interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader([]byte(manifest)))
+	for {
+		doc := yaml.MapSlice{}
+		err := decoder.Decode(&doc)
+		if err == io.EOF {
+			return docs, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(doc) > 0 {
+			docs = append(docs, doc)
+		}
+	}
+}
+
+// documentName returns the kind and name of a document
+func documentName(doc interface{}) string {
+	d, _ := doc.(yaml.MapSlice)
+	kind, _ := lookup(d, "kind")
+	metadata, _ := lookup(d, "metadata")
+	m, _ := metadata.(yaml.MapSlice)
+	name, _ := lookup(m, "name")
+	return fmt.Sprintf("%v/%v", kind, name)
+}
+
+// mergeValue merges a created value into an existing value
+func mergeValue(existing, created interface{}) interface{} {
+	switch e := existing.(type) {
+	case yaml.MapSlice:
+		c, ok := created.(yaml.MapSlice)
+		if !ok {
+			return created
+		}
+		if directive, _ := lookup(c, "$patch"); directive == "replace" {
+			return created
+		}
+		return mergeMapping(e, c)
+	case []interface{}:
+		c, ok := created.([]interface{})
+		if !ok {
+			return created
+		}
+		return mergeList(e, c)
+	}
+	return created
+}
+
+// mergeMapping merges the fields of a created mapping into an existing mapping, keeping the existing order
+func mergeMapping(existing, created yaml.MapSlice) yaml.MapSlice {
+	replaced := map[interface{}]bool{}
+	for _, fields := range exclusiveFields {
+		for _, field := range fields {
+			if _, ok := lookup(created, field); ok {
+				for _, f := range fields {
+					replaced[f] = true
+				}
+			}
+		}
+	}
+
+	merged := yaml.MapSlice{}
+	for _, item := range existing {
+		if value, ok := lookup(created, item.Key); ok {
+			merged = append(merged, yaml.MapItem{Key: item.Key, Value: mergeValue(item.Value, value)})
+		} else if !replaced[item.Key] {
+			merged = append(merged, item)
+		}
+	}
+	for _, item := range created {
+		if _, ok := lookup(existing, item.Key); !ok {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+// mergeList merges the items of a created list into an existing list. Items with the same name are
+// merged and other items are added unless the list already has them.
+func mergeList(existing, created []interface{}) []interface{} {
+	merged := append([]interface{}{}, existing...)
+	for _, c := range created {
+		found := false
+		for i, m := range merged {
+			if name, ok := itemName(c); ok {
+				if n, ok := itemName(m); ok && n == name {
+					merged[i], found = mergeValue(m, c), true
+					break
+				}
+			} else if reflect.DeepEqual(m, c) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, c)
+		}
+	}
+	return merged
+}
+
+// itemName returns the name of an item of a list, such as a container, or the name of the resource of
+// an autoscaler metric
+func itemName(item interface{}) (string, bool) {
+	m, ok := item.(yaml.MapSlice)
+	if !ok {
+		return "", false
+	}
+	if name, ok := lookup(m, "name"); ok {
+		return fmt.Sprintf("name=%v", name), true
+	}
+	if resource, ok := lookup(m, "resource"); ok {
+		if r, ok := resource.(yaml.MapSlice); ok {
+			if name, ok := lookup(r, "name"); ok {
+				return fmt.Sprintf("resource=%v", name), true
+			}
+		}
+	}
+	return "", false
+}
+
+// PatchedContainer returns the name of the first container of a patch, or "" when it patches no container
+func PatchedContainer(patch string) string {
+	docs, err := documents(patch)
+	if err != nil {
+		return ""
+	}
+	for _, containers := range values(docs, "containers") {
+		if c, ok := containers.([]interface{}); ok && len(c) > 0 {
+			if m, ok := c[0].(yaml.MapSlice); ok {
+				if name, ok := lookup(m, "name"); ok {
+					return fmt.Sprint(name)
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// MountPath returns the path a patch mounts the volume at, or "" when it does not mount the volume
+func MountPath(patch, volume string) string {
+	docs, err := documents(patch)
+	if err != nil {
+		return ""
+	}
+	for _, mounts := range values(docs, "volumeMounts") {
+		m, _ := mounts.([]interface{})
+		for _, mount := range m {
+			mount, _ := mount.(yaml.MapSlice)
+			if name, _ := lookup(mount, "name"); name == volume {
+				mountPath, _ := lookup(mount, "mountPath")
+				return fmt.Sprint(mountPath)
+			}
+		}
+	}
+	return ""
+}
+
+// values returns the values of the key in any mapping of the value, in document order
+func values(value interface{}, key string) []interface{} {
+	var found []interface{}
+	switch v := value.(type) {
+	case yaml.MapSlice:
+		for _, item := range v {
+			if item.Key == key {
+				found = append(found, item.Value)
+			}
+			found = append(found, values(item.Value, key)...)
+		}
+	case []interface{}:
+		for _, item := range v {
+			found = append(found, values(item, key)...)
+		}
+	}
+	return found
+}
+
+// PatchedReplicas returns the replica count set by a patch, or 0 when it sets none
+func PatchedReplicas(patch string) int {
+	docs, err := documents(patch)
+	if err != nil {
+		return 0
+	}
+	for _, replicas := range values(docs, "replicas") {
+		if n, ok := replicas.(int); ok {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,81 @@
+package kustomization
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const probePatch = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          livenessProbe:
+            httpGet:
+              path: /healthz
+              port: 8080
+            periodSeconds: 10
+`
+
+func TestKeepsManifestCreatedAgainUnchanged(t *testing.T) {
+	merged, err := MergeManifest(probePatch, probePatch)
+	assert.Nil(t, err)
+	assert.Equal(t, probePatch, merged)
+}
+
+func TestMergesProbeHandlerReplacingExistingHandler(t *testing.T) {
+	created := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          livenessProbe:
+            tcpSocket:
+              port: 8080
+`
+	merged, err := MergeManifest(probePatch, created)
+	assert.Nil(t, err)
+	assert.YAMLEq(t, `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          livenessProbe:
+            tcpSocket:
+              port: 8080
+            periodSeconds: 10
+`, merged)
+}
+
+func TestMergesProbeMarkedForReplacement(t *testing.T) {
+	created := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          livenessProbe:
+            $patch: replace
+            exec:
+              command:
+                - /check
+`
+	merged, err := MergeManifest(probePatch, created)
+	assert.Nil(t, err)
+	assert.Equal(t, created, merged)
+}
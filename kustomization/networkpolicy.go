@@ -0,0 +1,80 @@
+package kustomization
+
+import "text/template"
+
+func NetworkPolicy() *template.Template {
+	networkPolicy :=
+		`{{- define "peer"}}
+{{- if .Namespace}}
+        - namespaceSelector:
+            matchLabels:
+              kubernetes.io/metadata.name: {{.Namespace}}
+{{- if .App}}
+          podSelector:
+            matchLabels:
+              app: {{.App}}
+{{- end}}
+{{- else}}
+        - podSelector:
+            matchLabels:
+              app: {{.App}}
+{{- end}}
+{{- end -}}
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: {{.Name}}
+spec:
+  podSelector:
+    matchLabels:
+      app: {{.Name}}
+  policyTypes:
+    - Ingress
+{{- with .NetworkPolicy}}
+{{- if .RestrictEgress}}
+    - Egress
+{{- end}}
+{{- if .IngressPeers}}
+  ingress:
+    - from:
+{{- range .IngressPeers}}
+{{- template "peer" .}}
+{{- end}}
+{{- if $.Ports}}
+      ports:
+{{- range $.Ports}}
+        - protocol: {{.Protocol}}
+          port: {{.ContainerPort}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .RestrictEgress}}
+  egress:
+    - to:
+        - namespaceSelector:
+            matchLabels:
+              kubernetes.io/metadata.name: kube-system
+          podSelector:
+            matchLabels:
+              k8s-app: kube-dns
+      ports:
+        - protocol: UDP
+          port: 53
+        - protocol: TCP
+          port: 53
+{{- if .EgressPeers}}
+    - to:
+{{- range .EgressPeers}}
+{{- template "peer" .}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- end}}
+`
+
+	tmpl, err := template.New("networkpolicy").Parse(networkPolicy)
+	if err != nil {
+		panic("networkpolicy spec template is misconfigured")
+	}
+	return tmpl
+}
@@ -0,0 +1,30 @@
+package kustomization
+
+import "text/template"
+
+func PodDisruptionBudget() *template.Template {
+	pdb :=
+		`apiVersion: policy/v1
+kind: PodDisruptionBudget
+metadata:
+  name: {{.Name}}
+spec:
+{{- with .DisruptionBudget}}
+{{- if .MinAvailable}}
+  minAvailable: {{.MinAvailable}}
+{{- end}}
+{{- if .MaxUnavailable}}
+  maxUnavailable: {{.MaxUnavailable}}
+{{- end}}
+{{- end}}
+  selector:
+    matchLabels:
+      app: {{.Name}}
+`
+
+	tmpl, err := template.New("pdb").Parse(pdb)
+	if err != nil {
+		panic("pdb spec template is misconfigured")
+	}
+	return tmpl
+}
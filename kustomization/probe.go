@@ -0,0 +1,74 @@
+package kustomization
+
+import (
+	"strings"
+	"text/template"
+)
+
+// containerProbes is the template source for the liveness, readiness and startup probes of a container.
+var containerProbes = strings.Join([]string{
+	probe("livenessProbe", ".LivenessProbe", false),
+	probe("readinessProbe", ".ReadinessProbe", false),
+	probe("startupProbe", ".StartupProbe", false),
+}, "\n")
+
+// probe returns the template source for a container probe rendered from the given application field.
+// Probes replacing an existing probe's handler are marked to replace the existing probe when patched.
+func probe(name, field string, patch bool) string {
+	replace := ""
+	if patch {
+		replace = `
+{{- if .Handler}}
+  $patch: replace
+{{- end}}`
+	}
+	return `{{- with ` + field + `}}
+` + name + `:` + replace + `
+{{- if eq .Handler "httpGet"}}
+  httpGet:
+    path: {{.Path}}
+    port: {{.Port}}
+{{- else if eq .Handler "tcpSocket"}}
+  tcpSocket:
+    port: {{.Port}}
+{{- else if eq .Handler "exec"}}
+  exec:
+    command:
+{{- range .Command}}
+      - {{printf "%q" .}}
+{{- end}}
+{{- end}}
+{{- if .InitialDelaySeconds}}
+  initialDelaySeconds: {{.InitialDelaySeconds}}
+{{- end}}
+{{- if .PeriodSeconds}}
+  periodSeconds: {{.PeriodSeconds}}
+{{- end}}
+{{- if .TimeoutSeconds}}
+  timeoutSeconds: {{.TimeoutSeconds}}
+{{- end}}
+{{- if .FailureThreshold}}
+  failureThreshold: {{.FailureThreshold}}
+{{- end}}
+{{- if .SuccessThreshold}}
+  successThreshold: {{.SuccessThreshold}}
+{{- end}}
+{{- end}}`
+}
+
+func DeploymentProbePatch() *template.Template {
+	deploymentProbePatch :=
+		podSpecPatch(`containers:
+  - name: {{.ContainerName}}
+` + indent(4, strings.Join([]string{
+			probe("livenessProbe", ".LivenessProbe", true),
+			probe("readinessProbe", ".ReadinessProbe", true),
+			probe("startupProbe", ".StartupProbe", true),
+		}, "\n")))
+
+	tmpl, err := template.New("deployment-probe").Parse(deploymentProbePatch)
+	if err != nil {
+		panic("deploymentProbePatch spec template is misconfigured")
+	}
+	return tmpl
+}
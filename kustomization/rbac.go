@@ -0,0 +1,70 @@
+package kustomization
+
+import "text/template"
+
+func ServiceAccount() *template.Template {
+	serviceAccount :=
+		`apiVersion: v1
+kind: ServiceAccount
+metadata:
+  name: {{.ServiceAccount}}
+` + indent(2, labels) + `
+`
+
+	tmpl, err := template.New("serviceaccount").Parse(serviceAccount)
+	if err != nil {
+		panic("serviceaccount spec template is misconfigured")
+	}
+	return tmpl
+}
+
+func Role() *template.Template {
+	role :=
+		`apiVersion: rbac.authorization.k8s.io/v1
+kind: Role
+metadata:
+  name: {{.Name}}
+rules:
+{{- range .Rules}}
+  - apiGroups:
+      - {{printf "%q" .APIGroup}}
+    resources:
+{{- range .Resources}}
+      - {{printf "%q" .}}
+{{- end}}
+    verbs:
+{{- range .Verbs}}
+      - {{printf "%q" .}}
+{{- end}}
+{{- end}}
+`
+
+	tmpl, err := template.New("role").Parse(role)
+	if err != nil {
+		panic("role spec template is misconfigured")
+	}
+	return tmpl
+}
+
+func RoleBinding() *template.Template {
+	roleBinding :=
+		`apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: {{.Name}}
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: Role
+  name: {{.Name}}
+subjects:
+  - kind: ServiceAccount
+    name: {{.ServiceAccount}}
+    namespace: {{.Namespace}}
+`
+
+	tmpl, err := template.New("rolebinding").Parse(roleBinding)
+	if err != nil {
+		panic("rolebinding spec template is misconfigured")
+	}
+	return tmpl
+}
@@ -0,0 +1,208 @@
+package kustomization
+
+import (
+	"github.com/azunymous/easymodo/input"
+	"github.com/pkg/errors"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v2"
+	"path"
+	"reflect"
+	"strings"
+)
+
+// Read reads the kustomization.yaml in the given directory. The document is kept with the
+// kustomization, so that fields outside of the model and the order of fields are kept when it is
+// created again. Comments are not kept.
+func Read(fs afero.Fs, dir string) (*input.Kustomization, error) {
+	content, err := afero.ReadFile(fs, path.Join(dir, "kustomization.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	f := File{}
+	if err := yaml.Unmarshal(content, &f); err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", path.Join(dir, "kustomization.yaml"))
+	}
+	document := yaml.MapSlice{}
+	if err := yaml.Unmarshal(content, &document); err != nil {
+		return nil, errors.Wrapf(err, "could not parse %s", path.Join(dir, "kustomization.yaml"))
+	}
+
+	k := f.Kustomization()
+	k.Document = document
+	return k, nil
+}
+
+// Kustomization returns the kustomization defined by the file
+func (f File) Kustomization() *input.Kustomization {
+	k := &input.Kustomization{
+		Res:               f.Resources,
+		Patches:           f.PatchesStrategicMerge,
+		Config:            map[string][]string{},
+		Secrets:           map[string][]string{},
+		Namespace:         f.Namespace,
+		NamePrefix:        f.NamePrefix,
+		NameSuffix:        f.NameSuffix,
+		CommonLabels:      f.CommonLabels,
+		CommonAnnotations: f.CommonAnnotations,
+		Labels:            f.Labels,
+		Components:        f.Components,
+		HelmCharts:        f.HelmCharts,
+		GeneratorOptions:  f.GeneratorOptions,
+		Images:            f.Images,
+		Replicas:          f.Replicas,
+		TargetedPatches:   f.Patches,
+		JSONPatches:       f.PatchesJSON6902,
+	}
+	for _, g := range f.ConfigMapGenerator {
+		for _, file := range g.Files {
+			k.AddConfig(g.Name, file)
+		}
+		for _, env := range g.Envs {
+			k.AddConfigEnv(g.Name, env)
+		}
+		for _, literal := range g.Literals {
+			k.AddConfigLiteral(g.Name, literal)
+		}
+	}
+	for _, g := range f.SecretGenerator {
+		for _, env := range g.Envs {
+			k.AddSecret(g.Name, env)
+		}
+		for _, file := range g.Files {
+			k.AddSecretFile(g.Name, file)
+		}
+		for _, literal := range g.Literals {
+			k.AddSecretLiteral(g.Name, literal)
+		}
+	}
+	return k
+}
+
+// marshalOnto returns the YAML of the kustomization file merged onto the document it was read from
+func (f File) marshalOnto(document yaml.MapSlice) ([]byte, error) {
+	content, err := f.Marshal()
+	if err != nil {
+		return nil, err
+	}
+	updated := yaml.MapSlice{}
+	if err := yaml.Unmarshal(content, &updated); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(merge(document, updated, reflect.TypeOf(f)))
+}
+
+// merge returns the updated value of the given model type, keeping the order of the original value and
+// its fields outside of the model. Items of lists are matched by their name or path.
+func merge(original, updated interface{}, t reflect.Type) interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	switch o := original.(type) {
+	case yaml.MapSlice:
+		u, ok := updated.(yaml.MapSlice)
+		if !ok || (t.Kind() != reflect.Struct && t.Kind() != reflect.Map && t.Kind() != reflect.Interface) {
+			return updated
+		}
+		return mergeFields(o, u, t)
+	case []interface{}:
+		u, ok := updated.([]interface{})
+		if !ok || t.Kind() != reflect.Slice {
+			return updated
+		}
+		return mergeItems(o, u, t.Elem())
+	}
+	return updated
+}
+
+// mergeFields merges the fields of a mapping, dropping fields of the model that are no longer set and
+// adding new fields in the order of the model
+func mergeFields(original, updated yaml.MapSlice, t reflect.Type) yaml.MapSlice {
+	merged := yaml.MapSlice{}
+	for _, item := range original {
+		fieldType, known := modelField(t, item.Key)
+		if !known {
+			merged = append(merged, item)
+			continue
+		}
+		if value, ok := lookup(updated, item.Key); ok {
+			merged = append(merged, yaml.MapItem{Key: item.Key, Value: merge(item.Value, value, fieldType)})
+		}
+	}
+	for _, item := range updated {
+		if _, ok := lookup(original, item.Key); !ok {
+			merged = append(merged, item)
+		}
+	}
+	return merged
+}
+
+// mergeItems merges the items of a list, keeping the original order of matching items and adding new
+// items in the updated order. Lists of scalars are replaced.
+func mergeItems(original, updated []interface{}, t reflect.Type) []interface{} {
+	var merged []interface{}
+	matched := map[int]bool{}
+	for _, o := range original {
+		for i, u := range updated {
+			if !matched[i] && sameItem(o, u) {
+				merged = append(merged, merge(o, u, t))
+				matched[i] = true
+				break
+			}
+		}
+	}
+	if len(merged) == 0 {
+		return updated
+	}
+	for i, u := range updated {
+		if !matched[i] {
+			merged = append(merged, u)
+		}
+	}
+	return merged
+}
+
+// sameItem returns whether two mappings in a list have the same name, path or inline patch
+func sameItem(a, b interface{}) bool {
+	am, ok := a.(yaml.MapSlice)
+	bm, ok2 := b.(yaml.MapSlice)
+	if !ok || !ok2 {
+		return false
+	}
+	for _, key := range []string{"name", "path", "patch"} {
+		av, aok := lookup(am, key)
+		bv, bok := lookup(bm, key)
+		if aok || bok {
+			return aok && bok && reflect.DeepEqual(av, bv)
+		}
+	}
+	return false
+}
+
+// modelField returns the type of the field for a key of a mapping of the given model type. Every key of
+// a map or of an untyped value is part of the model.
+func modelField(t reflect.Type, key interface{}) (reflect.Type, bool) {
+	switch t.Kind() {
+	case reflect.Map, reflect.Interface:
+		if t.Kind() == reflect.Map {
+			return t.Elem(), true
+		}
+		return t, true
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			name := strings.Split(t.Field(i).Tag.Get("yaml"), ",")[0]
+			if name == key {
+				return t.Field(i).Type, true
+			}
+		}
+	}
+	return nil, false
+}
+
+func lookup(m yaml.MapSlice, key interface{}) (interface{}, bool) {
+	for _, item := range m {
+		if item.Key == key {
+			return item.Value, true
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,67 @@
+package kustomization
+
+import (
+	"github.com/azunymous/easymodo/fs"
+	"github.com/azunymous/easymodo/input"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestReadsKustomization(t *testing.T) {
+	k, err := Read(afero.NewOsFs(), filepath.Join("testdata", "existing"))
+	assert.Nil(t, err)
+
+	assert.Equal(t, "app-dev", k.Namespace)
+	assert.Equal(t, []string{"../base", "ingress.yaml"}, k.Res)
+	assert.Equal(t, []string{"deployment-config-patch.yaml"}, k.Patches)
+	assert.Equal(t, map[string][]string{"app-config": {"config.yaml"}}, k.Config)
+	assert.Equal(t, []input.Image{{Name: "app", NewTag: "v1.0.0"}}, k.Images)
+}
+
+func TestReadsMissingKustomization(t *testing.T) {
+	_, err := Read(afero.NewOsFs(), filepath.Join("testdata", "missing"))
+	assert.NotNil(t, err)
+}
+
+func TestChangesKustomizationInPlace(t *testing.T) {
+	k, err := Read(afero.NewOsFs(), filepath.Join("testdata", "existing"))
+	assert.Nil(t, err)
+
+	k.AddResource("hpa.yaml")
+	k.AddConfig("app-config", "extra.yaml")
+	k.AddImage(input.Image{Name: "app", NewTag: "v2.0.0"})
+	k.Patches = nil
+	k.SetCommon(map[string]string{"team": "payments"}, nil)
+
+	assertCreates(t, k, "modified")
+}
+
+func TestKeepsUnchangedKustomization(t *testing.T) {
+	k, err := Read(afero.NewOsFs(), filepath.Join("testdata", "existing"))
+	assert.Nil(t, err)
+
+	assertCreates(t, k, "existing")
+}
+
+func TestKeepsSecretLiteralsOfKustomization(t *testing.T) {
+	k, err := Read(afero.NewOsFs(), filepath.Join("testdata", "secret-literals"))
+	assert.Nil(t, err)
+
+	assert.Equal(t, map[string][]string{"app-credentials": {"USERNAME=app", "PASSWORD=hunter2"}}, k.SecretLiterals)
+	assertCreates(t, k, "secret-literals")
+}
+
+func assertCreates(t *testing.T, k *input.Kustomization, golden string) {
+	fs.SetFsTo(afero.NewMemMapFs())
+	files := fs.NewFileMap()
+	Create(k, files)
+	files.WriteAll("out", "")
+
+	actual, err := afero.ReadFile(fs.Get(), filepath.Join("out", "kustomization.yaml"))
+	assert.Nil(t, err)
+	expect, _ := ioutil.ReadFile(filepath.Join("testdata", golden, "kustomization.yaml"))
+	assert.Equal(t, string(expect), string(actual))
+}
@@ -0,0 +1,67 @@
+package kustomization
+
+import "text/template"
+
+func DeploymentSchedulingPatch() *template.Template {
+	deploymentSchedulingPatch :=
+		podSpecPatch(`{{- with .Scheduling}}
+{{- if .PriorityClassName}}
+priorityClassName: {{.PriorityClassName}}
+{{- end}}
+{{- if .NodeSelector}}
+nodeSelector:
+{{- range $key, $value := .NodeSelector}}
+  {{$key}}: {{printf "%q" $value}}
+{{- end}}
+{{- end}}
+{{- if .Tolerations}}
+tolerations:
+{{- range .Tolerations}}
+  - key: {{.Key}}
+    operator: {{.Operator}}
+{{- if .Value}}
+    value: {{printf "%q" .Value}}
+{{- end}}
+{{- if .Effect}}
+    effect: {{.Effect}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- with .AntiAffinity}}
+affinity:
+  podAntiAffinity:
+{{- if .Required}}
+    requiredDuringSchedulingIgnoredDuringExecution:
+      - topologyKey: {{.TopologyKey}}
+        labelSelector:
+          matchLabels:
+            app: {{$.Name}}
+{{- else}}
+    preferredDuringSchedulingIgnoredDuringExecution:
+      - weight: 100
+        podAffinityTerm:
+          topologyKey: {{.TopologyKey}}
+          labelSelector:
+            matchLabels:
+              app: {{$.Name}}
+{{- end}}
+{{- end}}
+{{- if .TopologySpread}}
+topologySpreadConstraints:
+{{- range .TopologySpread}}
+  - maxSkew: {{.MaxSkew}}
+    topologyKey: {{.TopologyKey}}
+    whenUnsatisfiable: {{.WhenUnsatisfiable}}
+    labelSelector:
+      matchLabels:
+        app: {{$.Name}}
+{{- end}}
+{{- end}}
+{{- end}}`)
+
+	tmpl, err := template.New("deployment-scheduling").Parse(deploymentSchedulingPatch)
+	if err != nil {
+		panic("deploymentSchedulingPatch spec template is misconfigured")
+	}
+	return tmpl
+}
@@ -0,0 +1,60 @@
+package kustomization
+
+import "text/template"
+
+func ExternalSecret() *template.Template {
+	externalSecret :=
+		`apiVersion: external-secrets.io/v1
+kind: ExternalSecret
+metadata:
+  name: {{.Name}}-secret
+` + indent(2, labels) + `
+spec:
+  refreshInterval: {{.ExternalSecret.RefreshInterval}}
+  secretStoreRef:
+    name: {{.ExternalSecret.Store}}
+    kind: {{.ExternalSecret.StoreKind}}
+  target:
+    name: {{.Name}}-secret
+    creationPolicy: Owner
+  data:
+{{- range .ExternalSecret.Data}}
+    - secretKey: {{.Key}}
+      remoteRef:
+        key: {{printf "%q" .RemoteKey}}
+{{- if .Property}}
+        property: {{printf "%q" .Property}}
+{{- end}}
+{{- end}}
+`
+
+	tmpl, err := template.New("externalsecret").Parse(externalSecret)
+	if err != nil {
+		panic("externalSecret spec template is misconfigured")
+	}
+	return tmpl
+}
+
+// Secret is the template for the application secret given to kubeseal for sealing, setting the
+// application's env as the secret data. It is never written to the overlay.
+func Secret() *template.Template {
+	secret :=
+		`apiVersion: v1
+kind: Secret
+metadata:
+  name: {{.Name}}-secret
+  namespace: {{.Namespace}}
+` + indent(2, labels) + `
+type: Opaque
+stringData:
+{{- range .Env}}
+  {{.Name}}: {{printf "%q" .Value}}
+{{- end}}
+`
+
+	tmpl, err := template.New("secret").Parse(secret)
+	if err != nil {
+		panic("secret spec template is misconfigured")
+	}
+	return tmpl
+}
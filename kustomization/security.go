@@ -0,0 +1,52 @@
+package kustomization
+
+import "text/template"
+
+// podSecurityContext is the template source for the pod security context of a security profile.
+var podSecurityContext = `{{- with .Security}}
+{{- if ne .Profile "none"}}
+securityContext:
+{{- if .Restricted}}
+  runAsNonRoot: true
+{{- end}}
+  seccompProfile:
+    type: RuntimeDefault
+{{- end}}
+{{- end}}`
+
+// containerSecurityContext is the template source for the container security context of a security
+// profile.
+var containerSecurityContext = `{{- with .Security}}
+{{- if ne .Profile "none"}}
+securityContext:
+  allowPrivilegeEscalation: false
+{{- if .Restricted}}
+  readOnlyRootFilesystem: true
+  capabilities:
+    drop:
+      - ALL
+{{- end}}
+{{- end}}
+{{- end}}`
+
+func DeploymentSecurityPatch() *template.Template {
+	deploymentSecurityPatch :=
+		podSpecPatch(`securityContext:
+{{- with .Security}}
+{{- if .RunAsUser}}
+  runAsUser: {{.RunAsUser}}
+{{- end}}
+{{- if .RunAsGroup}}
+  runAsGroup: {{.RunAsGroup}}
+{{- end}}
+{{- if .FSGroup}}
+  fsGroup: {{.FSGroup}}
+{{- end}}
+{{- end}}`)
+
+	tmpl, err := template.New("deployment-security").Parse(deploymentSecurityPatch)
+	if err != nil {
+		panic("deploymentSecurityPatch spec template is misconfigured")
+	}
+	return tmpl
+}
@@ -8,13 +8,24 @@ func Service() *template.Template {
 kind: Service
 metadata:
   name: {{.Name}}
+` + indent(2, labels) + `
 spec:
+{{- if .Headless}}
+  clusterIP: None
+{{- else if and .ServiceType (ne .ServiceType "ClusterIP")}}
+  type: {{.ServiceType}}
+{{- end}}
   selector:
     app: {{.Name}}
   ports:
+{{- range .Ports}}
     - protocol: {{.Protocol}}
+{{- if .Name}}
+      name: {{.Name}}
+{{- end}}
       port: {{.ContainerPort}}
-      targetPort: {{.ContainerPort}}
+      targetPort: {{if .Name}}{{.Name}}{{else}}{{.ContainerPort}}{{end}}
+{{- end}}
 `
 
 	tmpl, err := template.New("service").Parse(service)
@@ -23,3 +34,28 @@ spec:
 	}
 	return tmpl
 }
+
+func ServicePatch() *template.Template {
+	servicePatch :=
+		`apiVersion: v1
+kind: Service
+metadata:
+  name: {{.Name}}
+{{- if .ServiceAnnotations}}
+  annotations:
+{{- range $key, $value := .ServiceAnnotations}}
+    {{$key}}: {{printf "%q" $value}}
+{{- end}}
+{{- end}}
+{{- if .ServiceType}}
+spec:
+  type: {{.ServiceType}}
+{{- end}}
+`
+
+	tmpl, err := template.New("servicePatch").Parse(servicePatch)
+	if err != nil {
+		panic("service patch spec template is misconfigured")
+	}
+	return tmpl
+}
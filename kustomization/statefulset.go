@@ -0,0 +1,50 @@
+package kustomization
+
+import (
+	"text/template"
+)
+
+func StatefulSet() *template.Template {
+	statefulSet :=
+		`apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: {{.Name}}
+` + indent(2, labels) + `
+spec:
+  serviceName: {{.Name}}
+  replicas: 1
+  selector:
+    matchLabels:
+      app: {{.Name}}
+` + indent(2, podTemplate) + `
+  volumeClaimTemplates:
+    - metadata:
+        name: {{.Name}}-data
+      spec:
+        accessModes:
+          - ReadWriteOnce
+        resources:
+          requests:
+            storage: {{.StorageSize}}
+{{- range .ClaimTemplates}}
+    - metadata:
+        name: {{.Name}}
+      spec:
+        accessModes:
+          - ReadWriteOnce
+{{- if .StorageClass}}
+        storageClassName: {{.StorageClass}}
+{{- end}}
+        resources:
+          requests:
+            storage: {{.Size}}
+{{- end}}
+`
+
+	tmpl, err := template.New("statefulset").Parse(statefulSet)
+	if err != nil {
+		panic("statefulset spec template is misconfigured")
+	}
+	return tmpl
+}
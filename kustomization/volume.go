@@ -0,0 +1,87 @@
+package kustomization
+
+import "text/template"
+
+// volumeSources is the template source for the pod volumes of the application, backed by a claim or
+// an emptyDir.
+var volumeSources = `{{- range .PodVolumes}}
+  - name: {{.Name}}
+{{- if .Claim}}
+    persistentVolumeClaim:
+      claimName: {{$.Name}}-{{.Name}}
+{{- else}}
+    emptyDir: {}
+{{- end}}
+{{- end}}`
+
+func PersistentVolumeClaims() *template.Template {
+	persistentVolumeClaims :=
+		`{{range $i, $claim := .Claims}}{{if $i}}---
+{{end}}apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: {{$.Name}}-{{.Name}}
+` + indent(2, "{{with $}}"+labels+"{{end}}") + `
+spec:
+  accessModes:
+    - ReadWriteOnce
+{{- if .StorageClass}}
+  storageClassName: {{.StorageClass}}
+{{- end}}
+  resources:
+    requests:
+      storage: {{.Size}}
+{{end}}`
+
+	tmpl, err := template.New("pvc").Parse(persistentVolumeClaims)
+	if err != nil {
+		panic("persistentvolumeclaim spec template is misconfigured")
+	}
+	return tmpl
+}
+
+// PersistentVolumeClaimPatch is the template for overriding the storage class and size of the base
+// claims. Note the storage class of an existing claim cannot be changed and claims can only grow.
+func PersistentVolumeClaimPatch() *template.Template {
+	persistentVolumeClaimPatch :=
+		`{{range $i, $claim := .Claims}}{{if $i}}---
+{{end}}apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: {{$.Name}}-{{.Name}}
+spec:
+{{- if .StorageClass}}
+  storageClassName: {{.StorageClass}}
+{{- end}}
+{{- if .Size}}
+  resources:
+    requests:
+      storage: {{.Size}}
+{{- end}}
+{{end}}`
+
+	tmpl, err := template.New("pvc-patch").Parse(persistentVolumeClaimPatch)
+	if err != nil {
+		panic("persistentvolumeclaimPatch spec template is misconfigured")
+	}
+	return tmpl
+}
+
+func DeploymentVolumePatch() *template.Template {
+	deploymentVolumePatch :=
+		podSpecPatch(`containers:
+  - name: {{.ContainerName}}
+    volumeMounts:
+{{- range .Volumes}}
+      - mountPath: {{.MountPath}}
+        name: {{.Name}}
+{{- end}}
+volumes:
+` + volumeSources)
+
+	tmpl, err := template.New("deployment-volume").Parse(deploymentVolumePatch)
+	if err != nil {
+		panic("deploymentVolumePatch spec template is misconfigured")
+	}
+	return tmpl
+}
@@ -0,0 +1,159 @@
+package kustomization
+
+import (
+	"strings"
+	"text/template"
+)
+
+// podTemplate is the template source for the pod template shared by all workload kinds.
+var podTemplate = `template:
+  metadata:
+` + indent(4, labels) + `
+  spec:
+{{- if .Batch}}
+    restartPolicy: OnFailure
+{{- end}}
+{{- if .ServiceAccount}}
+    serviceAccountName: {{.ServiceAccount}}
+{{- end}}
+` + indent(4, podSecurityContext) + `
+` + indent(4, initContainers) + `
+    containers:
+      - name: {{.ContainerName}}
+        image: {{.Image}}
+{{- if .Ports}}
+        ports:
+{{- range .Ports}}
+          - containerPort: {{.ContainerPort}}
+{{- if .Name}}
+            name: {{.Name}}
+{{- end}}
+{{- if ne .Protocol "TCP"}}
+            protocol: {{.Protocol}}
+{{- end}}
+{{- end}}
+{{- end}}
+{{- if .Env}}
+        env:
+{{- range .Env}}
+          - name: {{.Name}}
+            value: {{printf "%q" .Value}}
+{{- end}}
+{{- end}}
+` + indent(8, containerProbes) + `
+` + indent(8, containerSecurityContext) + `
+{{- if or .Stateful .Volumes}}
+        volumeMounts:
+{{- if .Stateful}}
+          - mountPath: {{.DataPath}}
+            name: {{.Name}}-data
+{{- end}}
+{{- range .Volumes}}
+          - mountPath: {{.MountPath}}
+            name: {{.Name}}
+{{- if .ReadOnly}}
+            readOnly: true
+{{- end}}
+{{- end}}
+{{- end}}
+{{- range .Sidecars}}
+` + indent(6, container) + `
+{{- end}}
+{{- if .PodVolumes}}
+    volumes:
+` + indent(4, volumeSources) + `
+{{- end}}
+`
+
+// podSpecPatch returns the template source for a strategic merge patch of the application's
+// workload, placing the given pod spec under the pod template for the workload kind.
+func podSpecPatch(podSpec string) string {
+	return `apiVersion: {{.APIVersion}}
+kind: {{.Kind}}
+metadata:
+  name: {{.Name}}
+spec:
+{{- if eq .Kind "CronJob"}}
+  jobTemplate:
+    spec:
+      template:
+        spec:
+` + indent(10, podSpec) + `
+{{- else}}
+  template:
+    spec:
+` + indent(6, podSpec) + `
+{{- end}}`
+}
+
+// indent indents every line of the template source by the given number of spaces.
+func indent(spaces int, source string) string {
+	lines := strings.Split(strings.TrimRight(source, "\n"), "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = strings.Repeat(" ", spaces) + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+func DaemonSet() *template.Template {
+	daemonSet :=
+		`apiVersion: apps/v1
+kind: DaemonSet
+metadata:
+  name: {{.Name}}
+` + indent(2, labels) + `
+spec:
+  selector:
+    matchLabels:
+      app: {{.Name}}
+` + indent(2, podTemplate) + `
+`
+
+	tmpl, err := template.New("daemonset").Parse(daemonSet)
+	if err != nil {
+		panic("daemonset spec template is misconfigured")
+	}
+	return tmpl
+}
+
+func Job() *template.Template {
+	job :=
+		`apiVersion: batch/v1
+kind: Job
+metadata:
+  name: {{.Name}}
+` + indent(2, labels) + `
+spec:
+` + indent(2, podTemplate) + `
+`
+
+	tmpl, err := template.New("job").Parse(job)
+	if err != nil {
+		panic("job spec template is misconfigured")
+	}
+	return tmpl
+}
+
+func CronJob() *template.Template {
+	cronJob :=
+		`apiVersion: batch/v1
+kind: CronJob
+metadata:
+  name: {{.Name}}
+` + indent(2, labels) + `
+spec:
+  schedule: "{{.Schedule}}"
+  concurrencyPolicy: {{.ConcurrencyPolicy}}
+  jobTemplate:
+    spec:
+` + indent(6, podTemplate) + `
+`
+
+	tmpl, err := template.New("cronjob").Parse(cronJob)
+	if err != nil {
+		panic("cronjob spec template is misconfigured")
+	}
+	return tmpl
+}